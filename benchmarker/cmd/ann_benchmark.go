@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -32,6 +34,8 @@ import (
 	weaviategrpc "github.com/weaviate/weaviate/grpc/generated/protocol/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/types/known/structpb"
 )
@@ -39,9 +43,10 @@ import (
 type CompressionType byte
 
 const (
-	CompressionTypePQ   CompressionType = 0
-	CompressionTypeSQ   CompressionType = 1
-	CompressionTypeLASQ CompressionType = 2
+	CompressionTypePQ     CompressionType = 0
+	CompressionTypeSQ     CompressionType = 1
+	CompressionTypeLASQ   CompressionType = 2
+	CompressionTypeRaBitQ CompressionType = 3
 )
 
 // Batch of vectors and offset for writing to Weaviate
@@ -49,28 +54,39 @@ type Batch struct {
 	Vectors [][]float32
 	Offset  int
 	Filters []int
+	// VectorOffsets marks the start of each concatenated vector group within
+	// Vectors' rows, e.g. [0, 768, 1536] for two 768-dim groups concatenated
+	// into a 1536-dim row. Only populated for datasets built from multiple
+	// vector subsets (see ParquetDataset); nil otherwise.
+	VectorOffsets []int
 }
 
 // Weaviate https://github.com/weaviate/weaviate-chaos-engineering/tree/main/apps/ann-benchmarks style format
 // mixed camel / snake case for compatibility
 type ResultsJSONBenchmark struct {
-	Api              string  `json:"api"`
-	Ef               int     `json:"ef"`
-	EfConstruction   int     `json:"efConstruction"`
-	MaxConnections   int     `json:"maxConnections"`
-	Mean             float64 `json:"meanLatency"`
-	P99Latency       float64 `json:"p99Latency"`
-	QueriesPerSecond float64 `json:"qps"`
-	Shards           int     `json:"shards"`
-	Parallelization  int     `json:"parallelization"`
-	Limit            int     `json:"limit"`
-	ImportTime       float64 `json:"importTime"`
-	RunID            string  `json:"run_id"`
-	Dataset          string  `json:"dataset_file"`
-	Recall           float64 `json:"recall"`
-	HeapAllocBytes   float64 `json:"heap_alloc_bytes"`
-	HeapInuseBytes   float64 `json:"heap_inuse_bytes"`
-	HeapSysBytes     float64 `json:"heap_sys_bytes"`
+	Api               string          `json:"api"`
+	Ef                int             `json:"ef"`
+	EfConstruction    int             `json:"efConstruction"`
+	MaxConnections    int             `json:"maxConnections"`
+	Mean              float64         `json:"meanLatency"`
+	P99Latency        float64         `json:"p99Latency"`
+	QueriesPerSecond  float64         `json:"qps"`
+	Shards            int             `json:"shards"`
+	Parallelization   int             `json:"parallelization"`
+	Limit             int             `json:"limit"`
+	ImportTime        float64         `json:"importTime"`
+	RunID             string          `json:"run_id"`
+	Dataset           string          `json:"dataset_file"`
+	Recall            float64         `json:"recall"`
+	HeapAllocBytes    float64         `json:"heap_alloc_bytes"`
+	HeapInuseBytes    float64         `json:"heap_inuse_bytes"`
+	HeapSysBytes      float64         `json:"heap_sys_bytes"`
+	Histogram         NativeHistogram `json:"latency_histogram"`
+	QueriesTimedOut   int             `json:"queries_timed_out"`
+	FilterSelectivity float64         `json:"filter_selectivity,omitempty"`
+	IndexType         string          `json:"indexType,omitempty"`
+	BatchSize         int             `json:"batchSize,omitempty"`
+	RescoreLimit      int             `json:"rescoreLimit,omitempty"`
 }
 
 // Convert an int to a uuid formatted string
@@ -95,8 +111,17 @@ func intFromUUID(uuidStr string) int {
 	return int(val)
 }
 
-// Writes a single batch of vectors to Weaviate using gRPC
-func writeChunk(chunk *Batch, client *weaviategrpc.WeaviateClient, cfg *Config, namedVector string) {
+// Writes a single batch of vectors to Weaviate using gRPC. checkpoint
+// reserves [chunk.Offset, chunk.Offset+len(chunk.Vectors)) before sending
+// the batch and marks it committed or failed once the response comes back,
+// so a crashed run can resume without replaying already-written rows; a nil
+// checkpoint is a no-op.
+func writeChunk(chunk *Batch, client *weaviategrpc.WeaviateClient, cfg *Config, namedVector string, checkpoint *checkpointStore, checkpointKey string) {
+	rangeStart := chunk.Offset
+	rangeEnd := chunk.Offset + len(chunk.Vectors)
+	if err := checkpoint.reserve(checkpointKey, rangeStart, rangeEnd); err != nil {
+		log.Fatalf("Error reserving checkpoint range [%d,%d): %v", rangeStart, rangeEnd, err)
+	}
 
 	objects := make([]*weaviategrpc.BatchObject, len(chunk.Vectors))
 
@@ -126,8 +151,15 @@ func writeChunk(chunk *Batch, client *weaviategrpc.WeaviateClient, cfg *Config,
 			objects[i].Tenant = cfg.Tenant
 		}
 		if cfg.Filter {
+			var categoryValue interface{} = strconv.Itoa(chunk.Filters[i])
+			if cfg.FilterPredicate == "greaterThan" || cfg.FilterPredicate == "lessThan" {
+				// auto-schema infers a numeric type here instead of text, so
+				// --filter-predicate's range operators compare numerically
+				// rather than lexicographically.
+				categoryValue = float64(chunk.Filters[i])
+			}
 			nonRefProperties, err := structpb.NewStruct(map[string]interface{}{
-				"category": strconv.Itoa(chunk.Filters[i]),
+				"category": categoryValue,
 			})
 			if err != nil {
 				log.Fatalf("Error creating filtered struct: %v", err)
@@ -157,14 +189,26 @@ func writeChunk(chunk *Batch, client *weaviategrpc.WeaviateClient, cfg *Config,
 		log.Fatalf("could not send batch: %v", err)
 	}
 
+	hadErrors := false
 	for _, result := range response.GetErrors() {
 		if result.Error != "" {
+			hadErrors = true
 			log.Printf("Error for index %d: %s", result.Index, result.Error)
 		} else {
 			log.Printf("Successfully processed object at index %d", result.Index)
 		}
 	}
 
+	if hadErrors {
+		if err := checkpoint.fail(checkpointKey, rangeStart, rangeEnd); err != nil {
+			log.Printf("Error marking checkpoint range [%d,%d) failed: %v", rangeStart, rangeEnd, err)
+		}
+		return
+	}
+
+	if err := checkpoint.commit(checkpointKey, rangeStart, rangeEnd); err != nil {
+		log.Fatalf("Error committing checkpoint range [%d,%d): %v", rangeStart, rangeEnd, err)
+	}
 }
 
 func createClient(cfg *Config) *weaviate.Client {
@@ -188,6 +232,175 @@ func createClient(cfg *Config) *weaviate.Client {
 	return client
 }
 
+// buildGrpcTransportOption builds the grpc.DialOption carrying transport
+// credentials for cfg.Origin, used by every import/query gRPC dial in this
+// package. cfg.HttpScheme == "http" keeps the plaintext grpc.WithInsecure()
+// dial every gRPC site used before; "https" now supports verifying the
+// server against a real CA bundle and presenting a client certificate
+// (mTLS), not just the hardcoded InsecureSkipVerify: true these call sites
+// used to carry individually.
+func buildGrpcTransportOption(cfg *Config) grpc.DialOption {
+	if cfg.HttpScheme != "https" {
+		return grpc.WithInsecure()
+	}
+
+	if cfg.GrpcTLSSkipVerify && cfg.GrpcCAFile != "" {
+		log.Fatalf("--grpcTLSSkipVerify=true ignores --grpcCAFile entirely (tls.Config never consults RootCAs when InsecureSkipVerify is set), so the certificate would silently go unverified; pass --grpcTLSSkipVerify=false to verify against --grpcCAFile")
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: cfg.GrpcTLSSkipVerify,
+	}
+
+	if cfg.GrpcCAFile != "" {
+		caCert, err := os.ReadFile(cfg.GrpcCAFile)
+		if err != nil {
+			log.Fatalf("Error reading --grpcCAFile: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			log.Fatalf("Error parsing --grpcCAFile %q: no certificates found", cfg.GrpcCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.GrpcClientCertFile != "" || cfg.GrpcClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.GrpcClientCertFile, cfg.GrpcClientKeyFile)
+		if err != nil {
+			log.Fatalf("Error loading --grpcClientCertFile/--grpcClientKeyFile: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+}
+
+// grpcTokenCredentials attaches cfg.GrpcAuthToken as a bearer "authorization"
+// header on every RPC made over the connection it's dialed with - the gRPC
+// equivalent of cfg.HttpAuth's auth.ApiKey on the REST client - so a
+// benchmark can point at a Weaviate Cloud cluster or any deployment that
+// enforces auth on its gRPC port.
+type grpcTokenCredentials struct {
+	token      string
+	requireTLS bool
+}
+
+func (c grpcTokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + c.token}, nil
+}
+
+func (c grpcTokenCredentials) RequireTransportSecurity() bool {
+	return c.requireTLS
+}
+
+// grpcPerRPCDialOption returns a grpc.DialOption attaching cfg.GrpcAuthToken
+// to every RPC as per-RPC credentials, or nil if --grpcAuthToken isn't set.
+// Callers append it to their dial options only when non-nil, since
+// grpc.DialOption is an interface and a nil one isn't a no-op.
+func grpcPerRPCDialOption(cfg *Config) grpc.DialOption {
+	if cfg.GrpcAuthToken == "" {
+		return nil
+	}
+	return grpc.WithPerRPCCredentials(grpcTokenCredentials{
+		token:      cfg.GrpcAuthToken,
+		requireTLS: cfg.HttpScheme == "https",
+	})
+}
+
+// grpcTuningDialOptions builds the --grpcCompression/--grpcMaxRecvMsgSize/
+// --grpcInitialWindowSize/--grpcInitialConnWindowSize/--grpcWriteBufferSize/
+// --grpcKeepaliveTime dial options shared by every gRPC connection this
+// package dials. HNSW query payloads (vectors + k neighbors + metadata) are
+// large enough that the default flow-control window frequently bottlenecks
+// before the index itself does, especially at high --parallel counts, so
+// these mirror the knobs grpc's own benchmain exposes for the same reason.
+// --grpcKeepaliveTime additionally keeps long-running benchmarks from losing
+// an idle connection to an intermediate proxy or load balancer.
+func grpcTuningDialOptions(cfg *Config) []grpc.DialOption {
+	var opts []grpc.DialOption
+
+	switch cfg.GrpcCompression {
+	case "", "none":
+	case "gzip":
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
+	default:
+		log.Fatalf("Unsupported --grpcCompression %q, must be one of [none, gzip]", cfg.GrpcCompression)
+	}
+
+	if cfg.GrpcMaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(cfg.GrpcMaxRecvMsgSize)))
+	}
+	if cfg.GrpcInitialWindowSize > 0 {
+		opts = append(opts, grpc.WithInitialWindowSize(int32(cfg.GrpcInitialWindowSize)))
+	}
+	if cfg.GrpcInitialConnWindowSize > 0 {
+		opts = append(opts, grpc.WithInitialConnWindowSize(int32(cfg.GrpcInitialConnWindowSize)))
+	}
+	if cfg.GrpcWriteBufferSize > 0 {
+		opts = append(opts, grpc.WithWriteBufferSize(cfg.GrpcWriteBufferSize))
+	}
+	if cfg.GrpcKeepaliveTimeSeconds > 0 {
+		timeout := time.Duration(cfg.GrpcKeepaliveTimeout) * time.Second
+		if timeout <= 0 {
+			timeout = 20 * time.Second
+		}
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                time.Duration(cfg.GrpcKeepaliveTimeSeconds) * time.Second,
+			Timeout:             timeout,
+			PermitWithoutStream: cfg.GrpcKeepaliveNoStream,
+		}))
+	}
+
+	return opts
+}
+
+// retryCallOptions builds the grpc-ecosystem retry.CallOptions honoring
+// --grpcRetryMaxAttempts/--grpcRetryPerTryTimeout/--grpcRetryBackoffBaseMs/
+// --grpcRetryBackoffMaxMs, falling back to the fixed 100ms exponential
+// backoff every retrying gRPC dial in this package used before these flags
+// existed.
+func retryCallOptions(cfg *Config) []retry.CallOption {
+	base := 100 * time.Millisecond
+	if cfg.GrpcRetryBackoffBaseMs > 0 {
+		base = time.Duration(cfg.GrpcRetryBackoffBaseMs) * time.Millisecond
+	}
+
+	backoff := retry.BackoffExponential(base)
+	if cfg.GrpcRetryBackoffMaxMs > 0 {
+		backoffCap := time.Duration(cfg.GrpcRetryBackoffMaxMs) * time.Millisecond
+		backoff = func(ctx context.Context, attempt uint) time.Duration {
+			if d := retry.BackoffExponential(base)(ctx, attempt); d < backoffCap {
+				return d
+			}
+			return backoffCap
+		}
+	}
+
+	opts := []retry.CallOption{retry.WithBackoff(backoff)}
+
+	if cfg.GrpcRetryMaxAttempts > 0 {
+		opts = append(opts, retry.WithMax(uint(cfg.GrpcRetryMaxAttempts)))
+	}
+	if cfg.GrpcRetryPerTryTimeoutSec > 0 {
+		opts = append(opts, retry.WithPerRetryTimeout(time.Duration(cfg.GrpcRetryPerTryTimeoutSec)*time.Second))
+	}
+
+	return opts
+}
+
+// grpcRetryDialOption wraps retryCallOptions in a grpc.WithUnaryInterceptor
+// for gRPC dial sites that don't already install their own retry
+// interceptor. Returns nil (append only when non-nil) when no retry knobs
+// are set, since grpc.WithUnaryInterceptor overrides rather than chains with
+// a caller's own interceptor.
+func grpcRetryDialOption(cfg *Config) grpc.DialOption {
+	if cfg.GrpcRetryMaxAttempts <= 0 && cfg.GrpcRetryPerTryTimeoutSec <= 0 &&
+		cfg.GrpcRetryBackoffBaseMs <= 0 && cfg.GrpcRetryBackoffMaxMs <= 0 {
+		return nil
+	}
+	return grpc.WithUnaryInterceptor(retry.UnaryClientInterceptor(retryCallOptions(cfg)...))
+}
+
 // Re/create Weaviate schema
 func createSchema(cfg *Config, client *weaviate.Client) {
 
@@ -266,6 +479,14 @@ func createSchema(cfg *Config, client *weaviate.Client) {
 					"trainingLimit": cfg.TrainingLimit,
 				},
 			}
+		} else if cfg.RaBitQ == "auto" {
+			vectorIndexConfig["rabitq"] = map[string]interface{}{
+				"enabled":       true,
+				"bits":          cfg.RaBitQBits,
+				"rescoreLimit":  cfg.RescoreLimit,
+				"trainingLimit": cfg.TrainingLimit,
+				"rotation":      cfg.RaBitQRotation,
+			}
 		}
 	} else if cfg.IndexType == "flat" {
 		vectorIndexConfig = map[string]interface{}{
@@ -303,6 +524,14 @@ func createSchema(cfg *Config, client *weaviate.Client) {
 				"rescoreLimit": cfg.RescoreLimit,
 				"cache":        true,
 			}
+		} else if cfg.RaBitQ == "auto" {
+			vectorIndexConfig["hnsw"].(map[string]interface{})["rabitq"] = map[string]interface{}{
+				"enabled":       true,
+				"bits":          cfg.RaBitQBits,
+				"rescoreLimit":  cfg.RescoreLimit,
+				"trainingLimit": cfg.TrainingLimit,
+				"rotation":      cfg.RaBitQRotation,
+			}
 		}
 	} else {
 		log.Fatalf("Unknown index type %s", cfg.IndexType)
@@ -493,6 +722,14 @@ func enableCompression(cfg *Config, client *weaviate.Client, dimensions uint, co
 			"enabled":       true,
 			"trainingLimit": cfg.TrainingLimit,
 		}
+	case CompressionTypeRaBitQ:
+		vectorIndexConfig["rabitq"] = map[string]interface{}{
+			"enabled":       true,
+			"bits":          cfg.RaBitQBits,
+			"rescoreLimit":  cfg.RescoreLimit,
+			"trainingLimit": cfg.TrainingLimit,
+			"rotation":      cfg.RaBitQRotation,
+		}
 	}
 
 	classConfig.VectorIndexConfig = vectorIndexConfig
@@ -509,6 +746,8 @@ func enableCompression(cfg *Config, client *weaviate.Client, dimensions uint, co
 		log.Printf("Enabled SQ. Waiting for shard ready.\n")
 	case CompressionTypeLASQ:
 		log.Printf("Enabled LASQ. Waiting for shard ready.\n")
+	case CompressionTypeRaBitQ:
+		log.WithFields(log.Fields{"bits": cfg.RaBitQBits, "rotation": cfg.RaBitQRotation}).Printf("Enabled RaBitQ. Waiting for shard ready.\n")
 	}
 
 	start := time.Now()
@@ -547,6 +786,8 @@ func enableCompression(cfg *Config, client *weaviate.Client, dimensions uint, co
 		log.Printf("SQ Completed in %v\n", endTime.Sub(start))
 	case CompressionTypeLASQ:
 		log.Printf("LASQ Completed in %v\n", endTime.Sub(start))
+	case CompressionTypeRaBitQ:
+		log.Printf("RaBitQ Completed in %v\n", endTime.Sub(start))
 	}
 
 }
@@ -803,7 +1044,32 @@ func loadHdf5Train(file *hdf5.File, cfg *Config, offset uint, maxRows uint, upda
 		filters = loadHdf5Categories(file, "train_categories")
 	}
 
-	chunks := make(chan Batch, 10)
+	var checkpoint *checkpointStore
+	if cfg.CheckpointFile != "" {
+		checkpoint, err = openCheckpointStore(cfg.CheckpointFile)
+		if err != nil {
+			log.Fatalf("Error opening checkpoint file: %v", err)
+		}
+		defer checkpoint.Close()
+	}
+	key := checkpointKey(cfg)
+
+	if cfg.Resume && offset == 0 {
+		resumeOffset, err := checkpoint.resumeOffset(key)
+		if err != nil {
+			log.Fatalf("Error reading checkpoint resume offset: %v", err)
+		}
+		if resumeOffset > 0 {
+			log.Printf("--resume: skipping to row %d, already committed", resumeOffset)
+			offset = uint(resumeOffset)
+		}
+	}
+
+	summaryStop := make(chan struct{})
+	go logCheckpointSummary(checkpoint, key, 30*time.Second, summaryStop)
+	defer close(summaryStop)
+
+	chunks := make(chan Batch, cfg.ImportQueueDepth)
 
 	go func() {
 		loadHdf5Streaming(dataset, chunks, cfg, offset, maxRows, filters)
@@ -812,7 +1078,7 @@ func loadHdf5Train(file *hdf5.File, cfg *Config, offset uint, maxRows uint, upda
 
 	var wg sync.WaitGroup
 
-	for i := 0; i < 8; i++ {
+	for i := 0; i < cfg.ImportWorkers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -820,18 +1086,13 @@ func loadHdf5Train(file *hdf5.File, cfg *Config, offset uint, maxRows uint, upda
 			// Import workers will primary use the direct gRPC client
 			// If triggering deletes before import, we need to use the normal go client
 			grpcCtx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-			httpOption := grpc.WithInsecure()
-			if cfg.HttpScheme == "https" {
-				creds := credentials.NewTLS(&tls.Config{
-					InsecureSkipVerify: true,
-				})
-				httpOption = grpc.WithTransportCredentials(creds)
-			}
+			httpOption := buildGrpcTransportOption(cfg)
 			defer cancel()
-			opts := []retry.CallOption{
-				retry.WithBackoff(retry.BackoffExponential(100 * time.Millisecond)),
+			dialOptions := append([]grpc.DialOption{httpOption, grpc.WithUnaryInterceptor(retry.UnaryClientInterceptor(retryCallOptions(cfg)...))}, grpcTuningDialOptions(cfg)...)
+			if perRPC := grpcPerRPCDialOption(cfg); perRPC != nil {
+				dialOptions = append(dialOptions, perRPC)
 			}
-			grpcConn, err := grpc.DialContext(grpcCtx, cfg.Origin, httpOption, grpc.WithUnaryInterceptor(retry.UnaryClientInterceptor(opts...)))
+			grpcConn, err := grpc.DialContext(grpcCtx, cfg.Origin, dialOptions...)
 			if err != nil {
 				log.Fatalf("Did not connect: %v", err)
 			}
@@ -842,12 +1103,12 @@ func loadHdf5Train(file *hdf5.File, cfg *Config, offset uint, maxRows uint, upda
 			if cfg.MultiTargetVector > 0 {
 				for chunk := range chunks {
 					for i := 0; i < cfg.MultiTargetVector; i++ {
-						processChunk(chunk, &grpcClient, weaviateClient, cfg, fmt.Sprintf("named_vector_%d", i), updatePercent)
+						processChunk(chunk, &grpcClient, weaviateClient, cfg, fmt.Sprintf("named_vector_%d", i), updatePercent, checkpoint, key)
 					}
 				}
 			} else {
 				for chunk := range chunks {
-					processChunk(chunk, &grpcClient, weaviateClient, cfg, "", updatePercent)
+					processChunk(chunk, &grpcClient, weaviateClient, cfg, "", updatePercent, checkpoint, key)
 				}
 			}
 		}()
@@ -882,6 +1143,12 @@ func loadANNBenchmarksFile(file *hdf5.File, cfg *Config, client *weaviate.Client
 		enableCompression(cfg, client, dimensions, CompressionTypeLASQ)
 		loadHdf5Train(file, cfg, uint(cfg.TrainingLimit), 0, 0)
 
+	} else if cfg.RaBitQ == "enabled" {
+		dimensions := loadHdf5Train(file, cfg, 0, uint(cfg.TrainingLimit), 0)
+		log.Printf("Pausing to enable RaBitQ.")
+		enableCompression(cfg, client, dimensions, CompressionTypeRaBitQ)
+		loadHdf5Train(file, cfg, uint(cfg.TrainingLimit), 0, 0)
+
 	} else {
 		loadHdf5Train(file, cfg, 0, maxRows, 0)
 	}
@@ -921,15 +1188,132 @@ func parseEfValues(s string) ([]int, error) {
 	return nums, nil
 }
 
-func runQueries(cfg *Config, importTime time.Duration, testData [][]float32, neighbors [][]int, filters []int) {
+// parseIntListOrDefault parses a comma-separated "--xArray" flag the same
+// way parseEfValues does, except an empty flag (the common case: the user
+// only set the singular --x flag) falls back to that flag's own value as
+// the sole candidate, so every sweep dimension behaves the same whether or
+// not it's actually being swept.
+func parseIntListOrDefault(s string, fallback int) ([]int, error) {
+	if s == "" {
+		return []int{fallback}, nil
+	}
+	return parseEfValues(s)
+}
+
+// cartesianInts returns the Cartesian product of lists, e.g.
+// cartesianInts([][]int{{1,2},{10}}) -> [][]int{{1,10},{2,10}}.
+func cartesianInts(lists [][]int) [][]int {
+	combos := [][]int{{}}
+	for _, list := range lists {
+		var next [][]int
+		for _, combo := range combos {
+			for _, v := range list {
+				extended := append(append([]int(nil), combo...), v)
+				next = append(next, extended)
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+// filterSelectivity estimates, for a --filter-predicate and the query-side
+// filter values actually used, what fraction of the train set each query's
+// predicate keeps on average - the selectivity bucket (e.g. 1%, 10%, 50%)
+// that the filtered-ANN track of big-ann-benchmarks reports recall against.
+// Only the single train_categories/test_categories int column is wired up to
+// Weaviate's where-filter so far (see warnIfMultipleFilterColumns), so this
+// describes that one column rather than a composed multi-predicate filter.
+func filterSelectivity(trainFilters []int, testFilters []int, predicate string) float64 {
+	if len(trainFilters) == 0 || len(testFilters) == 0 {
+		return 0
+	}
+
+	var sum float64
+	switch predicate {
+	case "greaterThan", "lessThan":
+		sorted := append([]int(nil), trainFilters...)
+		sort.Ints(sorted)
+		for _, queryVal := range testFilters {
+			var matched int
+			if predicate == "greaterThan" {
+				matched = len(sorted) - sort.SearchInts(sorted, queryVal+1)
+			} else {
+				matched = sort.SearchInts(sorted, queryVal)
+			}
+			sum += float64(matched) / float64(len(sorted))
+		}
+	default:
+		counts := make(map[int]int, len(trainFilters))
+		for _, v := range trainFilters {
+			counts[v]++
+		}
+		for _, queryVal := range testFilters {
+			sum += float64(counts[queryVal]) / float64(len(trainFilters))
+		}
+	}
+	return sum / float64(len(testFilters))
+}
+
+// runWarmup issues a burst of throwaway queries against the test set before
+// the real benchmark loop below starts recording anything, so Go's
+// scheduler, HNSW's in-memory caches, and the gRPC connection itself are
+// all past their initial ramp-up by the time percentiles are captured. It's
+// a no-op unless --warmupQueries or --warmupDuration is set; --warmupDuration
+// takes priority when both are given, the same precedence --queryDuration
+// already has over a fixed query count.
+func runWarmup(cfg Config, testData Queries, neighbors Neighbors, testFilters []int) {
+	if cfg.WarmupQueries <= 0 && cfg.WarmupDuration <= 0 {
+		return
+	}
+
+	warmupCfg := cfg
+	if cfg.WarmupDuration > 0 {
+		warmupCfg.QueryDuration = cfg.WarmupDuration
+		benchmarkANNDuration(warmupCfg, testData, neighbors, testFilters)
+		return
+	}
+
+	warmupCfg.QueryDuration = 0
+	warmupCfg.QueryRepeat = cfg.WarmupQueries
+	benchmarkANN(warmupCfg, testData, neighbors, testFilters)
+}
+
+func runQueries(cfg *Config, importTime time.Duration, testData [][]float32, neighbors [][]int, trainFilters []int, testFilters []int) {
 
 	runID := strconv.FormatInt(time.Now().Unix(), 10)
+	if cfg.QueryOrder == "shuffled" || cfg.QueryOrder == "random" {
+		runID = fmt.Sprintf("%s-%s-seed%d", runID, cfg.QueryOrder, cfg.QuerySeed)
+	}
+
+	selectivity := float64(0)
+	if cfg.Filter {
+		selectivity = filterSelectivity(trainFilters, testFilters, cfg.FilterPredicate)
+	}
 
 	efCandidates, err := parseEfValues(cfg.EfArray)
 	if err != nil {
 		log.Fatalf("Error parsing efArray, expected commas separated format \"16,32,64\" but:%v\n", err)
 	}
 
+	parallelCandidates, err := parseIntListOrDefault(cfg.ParallelArray, cfg.Parallel)
+	if err != nil {
+		log.Fatalf("Error parsing parallelArray: %v\n", err)
+	}
+
+	limitCandidates, err := parseIntListOrDefault(cfg.LimitArray, cfg.Limit)
+	if err != nil {
+		log.Fatalf("Error parsing limitArray: %v\n", err)
+	}
+
+	// --parallel and --limit are pure query-time knobs - the Cartesian
+	// product of their candidates, plus ef, can be swept without touching
+	// the already-imported index. Build-time knobs (--maxConnections,
+	// --efConstruction, --rescoreLimit, --batchSize) can't: sweeping those
+	// is handled one reimport at a time by the caller via --sweepRebuild.
+	originalParallel, originalLimit := cfg.Parallel, cfg.Limit
+	defer func() { cfg.Parallel, cfg.Limit = originalParallel, originalLimit }()
+
 	// Read once at this point (after import and compaction delay) to get accurate memory stats
 	memstats := &Memstats{}
 	if !cfg.SkipMemoryStats {
@@ -942,63 +1326,89 @@ func runQueries(cfg *Config, importTime time.Duration, testData [][]float32, nei
 
 	client := createClient(cfg)
 
+	sinks, err := buildMetricsSinks(cfg)
+	if err != nil {
+		log.Fatalf("Error building metrics sinks: %v", err)
+	}
+	defer closeMetricsSinks(sinks)
+
+	runWarmup(*cfg, testData, neighbors, testFilters)
+
 	var benchmarkResultsMap []map[string]interface{}
 	for _, ef := range efCandidates {
 		updateEf(ef, cfg, client)
+		cfg.CurrentEf = ef
 
-		var result Results
+		for _, parallel := range parallelCandidates {
+			cfg.Parallel = parallel
 
-		if cfg.QueryDuration > 0 {
-			result = benchmarkANNDuration(*cfg, testData, neighbors, filters)
-		} else {
-			result = benchmarkANN(*cfg, testData, neighbors, filters)
-		}
-
-		log.WithFields(log.Fields{"mean": result.Mean, "qps": result.QueriesPerSecond, "recall": result.Recall,
-			"parallel": cfg.Parallel, "limit": cfg.Limit,
-			"api": cfg.API, "ef": ef, "count": result.Total, "failed": result.Failed}).Info("Benchmark result")
-
-		dataset := filepath.Base(cfg.BenchmarkFile)
-
-		var resultMap map[string]interface{}
-
-		benchResult := ResultsJSONBenchmark{
-			Api:              cfg.API,
-			Ef:               ef,
-			EfConstruction:   cfg.EfConstruction,
-			MaxConnections:   cfg.MaxConnections,
-			Mean:             result.Mean.Seconds(),
-			P99Latency:       result.Percentiles[len(result.Percentiles)-1].Seconds(),
-			QueriesPerSecond: result.QueriesPerSecond,
-			Shards:           cfg.Shards,
-			Parallelization:  cfg.Parallel,
-			Limit:            cfg.Limit,
-			ImportTime:       importTime.Seconds(),
-			RunID:            runID,
-			Dataset:          dataset,
-			Recall:           result.Recall,
-			HeapAllocBytes:   memstats.HeapAllocBytes,
-			HeapInuseBytes:   memstats.HeapInuseBytes,
-			HeapSysBytes:     memstats.HeapSysBytes,
-		}
-
-		jsonData, err := json.Marshal(benchResult)
-		if err != nil {
-			log.Fatalf("Error converting result to json")
-		}
+			for _, limit := range limitCandidates {
+				cfg.Limit = limit
 
-		if err := json.Unmarshal(jsonData, &resultMap); err != nil {
-			log.Fatalf("Error converting json to map")
-		}
+				var result Results
 
-		if cfg.LabelMap != nil {
-			for key, value := range cfg.LabelMap {
-				resultMap[key] = value
-			}
-		}
+				queryProfile := startProfile(cfg, runID, fmt.Sprintf("query-ef%d-p%d-l%d", ef, parallel, limit))
+				if cfg.QueryDuration > 0 {
+					result = benchmarkANNDuration(*cfg, testData, neighbors, testFilters)
+				} else {
+					result = benchmarkANN(*cfg, testData, neighbors, testFilters)
+				}
+				queryProfile.stop()
+
+				log.WithFields(log.Fields{"mean": result.Mean, "qps": result.QueriesPerSecond, "recall": result.Recall,
+					"parallel": cfg.Parallel, "limit": cfg.Limit,
+					"api": cfg.API, "ef": ef, "count": result.Total, "failed": result.Failed}).Info("Benchmark result")
+
+				dataset := filepath.Base(cfg.BenchmarkFile)
+
+				var resultMap map[string]interface{}
+
+				benchResult := ResultsJSONBenchmark{
+					Api:               cfg.API,
+					Ef:                ef,
+					EfConstruction:    cfg.EfConstruction,
+					MaxConnections:    cfg.MaxConnections,
+					Mean:              result.Mean.Seconds(),
+					P99Latency:        result.Percentiles[len(result.Percentiles)-1].Seconds(),
+					QueriesPerSecond:  result.QueriesPerSecond,
+					Shards:            cfg.Shards,
+					Parallelization:   cfg.Parallel,
+					Limit:             cfg.Limit,
+					ImportTime:        importTime.Seconds(),
+					RunID:             runID,
+					Dataset:           dataset,
+					Recall:            result.Recall,
+					HeapAllocBytes:    memstats.HeapAllocBytes,
+					HeapInuseBytes:    memstats.HeapInuseBytes,
+					HeapSysBytes:      memstats.HeapSysBytes,
+					Histogram:         result.Histogram,
+					QueriesTimedOut:   result.TimedOut,
+					FilterSelectivity: selectivity,
+					IndexType:         cfg.IndexType,
+					BatchSize:         cfg.BatchSize,
+					RescoreLimit:      cfg.RescoreLimit,
+				}
+
+				jsonData, err := json.Marshal(benchResult)
+				if err != nil {
+					log.Fatalf("Error converting result to json")
+				}
+
+				if err := json.Unmarshal(jsonData, &resultMap); err != nil {
+					log.Fatalf("Error converting json to map")
+				}
 
-		benchmarkResultsMap = append(benchmarkResultsMap, resultMap)
+				if cfg.LabelMap != nil {
+					for key, value := range cfg.LabelMap {
+						resultMap[key] = value
+					}
+				}
 
+				publishToSinks(context.Background(), sinks, &benchResult)
+
+				benchmarkResultsMap = append(benchmarkResultsMap, resultMap)
+			}
+		}
 	}
 
 	data, err := json.MarshalIndent(benchmarkResultsMap, "", "    ")
@@ -1012,6 +1422,10 @@ func runQueries(cfg *Config, importTime time.Duration, testData [][]float32, nei
 	if err != nil {
 		log.Fatalf("Error writing benchmark results to file: %v", err)
 	}
+
+	if cfg.Baseline != "" {
+		compareToBaseline(cfg, benchmarkResultsMap)
+	}
 }
 
 var annBenchmarkCommand = &cobra.Command{
@@ -1029,82 +1443,190 @@ var annBenchmarkCommand = &cobra.Command{
 
 		cfg.parseLabels()
 
-		file, err := hdf5.OpenFile(cfg.BenchmarkFile, hdf5.F_ACC_RDONLY)
+		if cfg.IndexType == "opensearch-knn" {
+			runOpenSearchBenchmark(&cfg)
+			return
+		}
+
+		if cfg.NumpyDir != "" {
+			runNumpyBenchmark(&cfg)
+			return
+		}
+
+		benchmarkFile, err := resolveDatasetFile(&cfg)
+		if err != nil {
+			log.Fatalf("Error resolving --dataset-url: %v\n", err)
+		}
+
+		file, err := hdf5.OpenFile(benchmarkFile, hdf5.F_ACC_RDONLY)
 		if err != nil {
 			log.Fatalf("Error opening file: %v\n", err)
 		}
 		defer file.Close()
 
-		client := createClient(&cfg)
+		buildCombos, err := buildSweepCombos(&cfg)
+		if err != nil {
+			log.Fatalf("Error parsing sweep arrays: %v\n", err)
+		}
 
-		importTime := 0 * time.Second
+		if len(buildCombos) > 1 && !cfg.SweepRebuild {
+			log.Warnf("%d build-time combinations requested (maxConnectionsArray/efConstructionArray/rescoreLimitArray/batchSizeArray) but --sweepRebuild is not set; running only the first combination", len(buildCombos))
+			buildCombos = buildCombos[:1]
+		}
 
-		if !cfg.QueryOnly {
+		for _, combo := range buildCombos {
+			runCfg := cfg
+			combo.apply(&runCfg)
+			runAnnBenchmarkOnce(runCfg, file)
+		}
+	},
+}
 
-			if !cfg.ExistingSchema {
-				createSchema(&cfg, client)
-			}
+// sweepCombo is one concrete assignment of the build-time sweep parameters
+// (--maxConnectionsArray, --efConstructionArray, --rescoreLimitArray,
+// --batchSizeArray) that createSchema/loadANNBenchmarksFile need to have
+// settled before import, unlike --parallel/--limit/--efArray which
+// runQueries can sweep after a single import.
+type sweepCombo struct {
+	maxConnections int
+	efConstruction int
+	rescoreLimit   int
+	batchSize      int
+}
 
-			log.WithFields(log.Fields{"index": cfg.IndexType, "efC": cfg.EfConstruction, "m": cfg.MaxConnections, "shards": cfg.Shards,
-				"distance": cfg.DistanceMetric, "dataset": cfg.BenchmarkFile}).Info("Starting import")
+func (c sweepCombo) apply(cfg *Config) {
+	cfg.MaxConnections = c.maxConnections
+	cfg.EfConstruction = c.efConstruction
+	cfg.RescoreLimit = c.rescoreLimit
+	cfg.BatchSize = c.batchSize
+}
 
-			if cfg.NumTenants > 0 {
-				importTime = loadHdf5MultiTenant(file, &cfg, client)
-			} else {
-				importTime = loadANNBenchmarksFile(file, &cfg, client, 0)
+// buildSweepCombos expands cfg's *Array flags for the build-time sweep
+// parameters into the Cartesian product of sweepCombos to import and
+// benchmark. Any array left unset falls back to the corresponding singular
+// flag's value, so the common case (no sweep) produces exactly one combo.
+func buildSweepCombos(cfg *Config) ([]sweepCombo, error) {
+	maxConnections, err := parseIntListOrDefault(cfg.MaxConnectionsArray, cfg.MaxConnections)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing maxConnectionsArray: %w", err)
+	}
+
+	efConstruction, err := parseIntListOrDefault(cfg.EfConstructionArray, cfg.EfConstruction)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing efConstructionArray: %w", err)
+	}
+
+	rescoreLimit, err := parseIntListOrDefault(cfg.RescoreLimitArray, cfg.RescoreLimit)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing rescoreLimitArray: %w", err)
+	}
+
+	batchSize, err := parseIntListOrDefault(cfg.BatchSizeArray, cfg.BatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing batchSizeArray: %w", err)
+	}
+
+	combos := make([]sweepCombo, 0, len(maxConnections)*len(efConstruction)*len(rescoreLimit)*len(batchSize))
+	for _, product := range cartesianInts([][]int{maxConnections, efConstruction, rescoreLimit, batchSize}) {
+		combos = append(combos, sweepCombo{
+			maxConnections: product[0],
+			efConstruction: product[1],
+			rescoreLimit:   product[2],
+			batchSize:      product[3],
+		})
+	}
+	return combos, nil
+}
+
+// runAnnBenchmarkOnce runs the import (unless --queryOnly) and query phases
+// for a single build-time sweepCombo; buildSweepCombos' caller runs it once
+// per combination when --sweepRebuild is set.
+func runAnnBenchmarkOnce(cfg Config, file *hdf5.File) {
+	client := createClient(&cfg)
+
+	importTime := 0 * time.Second
+
+	if !cfg.QueryOnly {
+
+		if !cfg.ExistingSchema {
+			resuming := false
+			if cfg.Resume {
+				hasProgress, err := checkpointHasProgress(&cfg)
+				if err != nil {
+					log.Fatalf("Error reading checkpoint file: %v", err)
+				}
+				resuming = hasProgress
 			}
 
-			sleepDuration := time.Duration(cfg.QueryDelaySeconds) * time.Second
-			log.Printf("Waiting for %s to allow for compaction etc\n", sleepDuration)
-			time.Sleep(sleepDuration)
+			if resuming {
+				log.Printf("--resume: checkpoint found for class %s, skipping schema recreation", cfg.ClassName)
+			} else {
+				createSchema(&cfg, client)
+			}
 		}
 
 		log.WithFields(log.Fields{"index": cfg.IndexType, "efC": cfg.EfConstruction, "m": cfg.MaxConnections, "shards": cfg.Shards,
-			"distance": cfg.DistanceMetric, "dataset": cfg.BenchmarkFile}).Info("Benchmark configuration")
+			"distance": cfg.DistanceMetric, "dataset": cfg.BenchmarkFile}).Info("Starting import")
 
-		neighbors := loadHdf5Neighbors(file, "neighbors")
-		testData := loadHdf5Float32(file, "test")
-		testFilters := make([]int, 0)
-		if cfg.Filter {
-			testFilters = loadHdf5Categories(file, "test_categories")
+		importProfile := startProfile(&cfg, strconv.FormatInt(time.Now().Unix(), 10), "import")
+		if cfg.NumTenants > 0 {
+			importTime = loadHdf5MultiTenant(file, &cfg, client)
+		} else {
+			importTime = loadANNBenchmarksFile(file, &cfg, client, 0)
 		}
+		importProfile.stop()
+
+		sleepDuration := time.Duration(cfg.QueryDelaySeconds) * time.Second
+		log.Printf("Waiting for %s to allow for compaction etc\n", sleepDuration)
+		time.Sleep(sleepDuration)
+	}
 
-		runQueries(&cfg, importTime, testData, neighbors, testFilters)
+	log.WithFields(log.Fields{"index": cfg.IndexType, "efC": cfg.EfConstruction, "m": cfg.MaxConnections, "shards": cfg.Shards,
+		"distance": cfg.DistanceMetric, "dataset": cfg.BenchmarkFile}).Info("Benchmark configuration")
 
-		if cfg.performUpdates() {
+	neighbors := loadHdf5Neighbors(file, "neighbors")
+	testData := loadHdf5Float32(file, "test")
+	testFilters := make([]int, 0)
+	trainFilters := make([]int, 0)
+	if cfg.Filter {
+		testFilters = loadHdf5Categories(file, "test_categories")
+		trainFilters = loadHdf5Categories(file, "train_categories")
+	}
 
-			totalRowCount, _ := calculateHdf5TrainExtent(file, &cfg)
-			updateRowCount := uint(math.Floor(float64(totalRowCount) * cfg.UpdatePercentage))
+	runQueries(&cfg, importTime, testData, neighbors, trainFilters, testFilters)
 
-			log.Printf("Performing %d update iterations\n", cfg.UpdateIterations)
+	if cfg.performUpdates() {
 
-			for i := 0; i < cfg.UpdateIterations; i++ {
+		totalRowCount, _ := calculateHdf5TrainExtent(file, &cfg)
+		updateRowCount := uint(math.Floor(float64(totalRowCount) * cfg.UpdatePercentage))
 
-				startTime := time.Now()
+		log.Printf("Performing %d update iterations\n", cfg.UpdateIterations)
 
-				if cfg.UpdateRandomized {
-					loadHdf5Train(file, &cfg, 0, 0, float32(cfg.UpdatePercentage))
-				} else {
-					deleteUuidRange(&cfg, client, 0, int(updateRowCount))
-					loadHdf5Train(file, &cfg, 0, updateRowCount, 0)
-				}
+		for i := 0; i < cfg.UpdateIterations; i++ {
 
-				log.WithFields(log.Fields{"duration": time.Since(startTime)}).Printf("Total delete and update time\n")
+			startTime := time.Now()
 
-				if !cfg.SkipTombstonesEmpty {
-					err := waitTombstonesEmpty(&cfg)
-					if err != nil {
-						log.Fatalf("Error waiting for tombstones to be empty: %v", err)
-					}
-				}
+			if cfg.UpdateRandomized {
+				loadHdf5Train(file, &cfg, 0, 0, float32(cfg.UpdatePercentage))
+			} else {
+				deleteUuidRange(&cfg, client, 0, int(updateRowCount))
+				loadHdf5Train(file, &cfg, 0, updateRowCount, 0)
+			}
 
-				runQueries(&cfg, importTime, testData, neighbors, testFilters)
+			log.WithFields(log.Fields{"duration": time.Since(startTime)}).Printf("Total delete and update time\n")
 
+			if !cfg.SkipTombstonesEmpty {
+				err := waitTombstonesEmpty(&cfg)
+				if err != nil {
+					log.Fatalf("Error waiting for tombstones to be empty: %v", err)
+				}
 			}
 
+			runQueries(&cfg, importTime, testData, neighbors, trainFilters, testFilters)
+
 		}
 
-	},
+	}
 }
 
 func initAnnBenchmark() {
@@ -1136,6 +1658,12 @@ func initAnnBenchmark() {
 		"sq", "disabled", "Set SQ (disabled, auto, or enabled) (default disabled)")
 	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.LASQ,
 		"lasq", "disabled", "Set LASQ (disabled, auto, or enabled) (default disabled)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.RaBitQ,
+		"rabitq", "disabled", "Set RaBitQ (disabled, auto, or enabled) (default disabled)")
+	annBenchmarkCommand.PersistentFlags().UintVar(&globalConfig.RaBitQBits,
+		"rabitqBits", 1, "Set RaBitQ bits per dimension (1, 2, or 4)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.RaBitQRotation,
+		"rabitqRotation", "hadamard", "Set RaBitQ rotation (hadamard or random-orthogonal)")
 	annBenchmarkCommand.PersistentFlags().UintVar(&globalConfig.PQRatio,
 		"pqRatio", 4, "Set PQ segments = dimensions / ratio (must divide evenly default 4)")
 	annBenchmarkCommand.PersistentFlags().UintVar(&globalConfig.PQSegments,
@@ -1153,7 +1681,7 @@ func initAnnBenchmark() {
 	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.EfArray,
 		"efArray", "16,24,32,48,64,96,128,256,512", "Array of ef parameters as comma separated list")
 	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.IndexType,
-		"indexType", "hnsw", "Index type (hnsw or flat)")
+		"indexType", "hnsw", "Index type (hnsw, flat, dynamic, or opensearch-knn to benchmark an OpenSearch/Elasticsearch cluster instead of Weaviate)")
 	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.MultiTargetVector,
 		"MultiTargetVector", 0, "Number of multiple target vectors (default 0)")
 	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.MaxConnections,
@@ -1204,29 +1732,229 @@ func initAnnBenchmark() {
 		"flatSearchCutoff", 40000, "Flat search cut off (default 40 000)")
 	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.FilterStrategy,
 		"filterStrategy", "sweeping", "Use a different filter strategy (options are sweeping or acorn)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.FilterPredicate,
+		"filter-predicate", "equal", "Predicate to apply --filter's category column as (equal, greaterThan, or lessThan); ranges let recall be compared across selectivity buckets (default equal)")
 	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.ReplicationFactor,
 		"replicationFactor", 1, "Replication factor")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.PushGatewayURL,
+		"push-gateway", "", "Prometheus Pushgateway URL to push results to after each run (default disabled)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.PushGatewayJob,
+		"push-job", "weaviate-benchmarker", "Job name to use when pushing to the Pushgateway")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.PushGatewayInstance,
+		"push-instance", "benchmarker", "Instance name to use when pushing to the Pushgateway")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.RemoteWriteConfig.URL,
+		"remote-write-url", "", "Prometheus remote_write endpoint to push results to after each run (default disabled), e.g. for Cortex/Thanos/VictoriaMetrics/Mimir")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.RemoteWriteConfig.Username,
+		"remote-write-username", "", "Basic auth username for --remote-write-url")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.RemoteWriteConfig.Password,
+		"remote-write-password", "", "Basic auth password for --remote-write-url")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.RemoteWriteConfig.BearerToken,
+		"remote-write-bearer-token", "", "Bearer token for --remote-write-url; takes precedence over --remote-write-username/--remote-write-password")
+	annBenchmarkCommand.PersistentFlags().StringToStringVar(&globalConfig.RemoteWriteConfig.Headers,
+		"remote-write-header", nil, "Additional key=value header to send with --remote-write-url requests, may be repeated")
+	annBenchmarkCommand.PersistentFlags().StringArrayVar(&globalConfig.Sinks,
+		"sink", nil, "Metrics sink to publish each completed run's results to, one of [influxdb, pushgateway, remote_write, statsd, file]; may be repeated to fan out to several")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.StatsDAddress,
+		"statsd-address", "", "host:port of a StatsD/DogStatsD agent for the statsd sink")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.StatsDPrefix,
+		"statsd-prefix", "weaviate_benchmark", "Metric name prefix for the statsd sink")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.SinkFile,
+		"sink-file", "", "Path to a JSON-lines file to append each completed run's results to, for the file sink")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.QueryTimeoutSeconds,
+		"query-timeout", 30, "Per-query timeout in seconds; a query that exceeds this is counted as a failure, not retried")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.RunDeadlineSeconds,
+		"run-deadline", 0, "Overall deadline in seconds for a single ef run's query phase; workers stop issuing new queries once it fires (0 disables)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.FilterColumn,
+		"filter-column", "", "Name of a categorical/int label column in a parquet hub dataset to use as the filter tag (used for logging only until the hub reader exposes companion columns; see --filter-selectivity)")
+	annBenchmarkCommand.PersistentFlags().Float64Var(&globalConfig.FilterSelectivity,
+		"filter-selectivity", 0, "Target selectivity (0-1) for synthetic per-row filter tags on parquet hub datasets that have no natural label column, e.g. 0.1 for ~10%% of rows matching (default disabled)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.MultiVectorSubsets,
+		"multi-vector-subsets", "", "Comma-separated list of additional hub dataset subsets whose vectors are concatenated onto each row, for benchmarking hybrid/multi-field embeddings")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.NumpyDir,
+		"numpy-dir", "", "Path to a directory of train.npy/test.npy/neighbors.npy (and optional *_categories.npy), or a single .npz bundle, to benchmark instead of --vectors' HDF5 file")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.ReaderParallel,
+		"reader-parallel", 1, "Number of parallel worker goroutines reading HDF5 train batches during import (default 1, preserves sequential reads)")
+	annBenchmarkCommand.PersistentFlags().BoolVar(&globalConfig.RecordPerQuery,
+		"record-per-query", false, "Record per-query latency/recall/ndcg arrays in the JSON results so they can be fed into \"benchmarker compare\"")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.QueryPattern,
+		"query-pattern", "closed", "Query load pattern, one of [closed, open]; open dispatches queries on a schedule independent of response time and also reports response-time (queueing-inclusive) percentiles, avoiding the closed-loop coordinated-omission problem")
+	annBenchmarkCommand.PersistentFlags().Float64Var(&globalConfig.RateQPS,
+		"rate-qps", 100, "Target queries/second for --query-pattern=open")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.RateDistribution,
+		"rate-distribution", "poisson", "Inter-query arrival schedule for --query-pattern=open, one of [poisson, fixed]")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.PrometheusConfig.ScrapePort,
+		"prometheus-scrape-port", 0, "If set, serve live per-query latency/recall/NDCG metrics on this port's /metrics endpoint for the duration of the run (default disabled)")
+	annBenchmarkCommand.PersistentFlags().BoolVar(&globalConfig.PrometheusConfig.Enabled,
+		"prometheus-push", false, "Also push the live metrics registry (with real latency histograms) to --prometheus-push-url once the run completes")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.PrometheusConfig.PushURL,
+		"prometheus-push-url", "", "Prometheus Pushgateway URL for --prometheus-push")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.PrometheusConfig.JobName,
+		"prometheus-job", "weaviate-benchmarker", "Job name to use when pushing with --prometheus-push")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.MaxRetries,
+		"max-retries", 0, "Number of times to retry a grpc query that fails with a retryable status code (default 0, disabled)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.RetryableCodes,
+		"retryable-codes", "", "Comma-separated grpc status codes to retry on, e.g. \"Unavailable,ResourceExhausted\" (default: Unavailable, DeadlineExceeded, ResourceExhausted)")
+	annBenchmarkCommand.PersistentFlags().BoolVar(&globalConfig.GraphiteConfig.Enabled,
+		"graphite-push", false, "Stream live qps/latency/recall/NDCG/heap metrics to a Graphite/Carbon backend for the duration of the run")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.GraphiteConfig.Address,
+		"graphite-address", "", "host:port of the Carbon line-receiver for --graphite-push")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.GraphiteConfig.Protocol,
+		"graphite-protocol", "tcp", "Protocol to speak to --graphite-address with, one of [tcp, udp]")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.GraphiteConfig.Prefix,
+		"graphite-prefix", "weaviate_benchmark", "Metric name prefix for --graphite-push")
+	annBenchmarkCommand.PersistentFlags().DurationVar(&globalConfig.GraphiteConfig.PushPeriod,
+		"graphite-push-period", 10*time.Second, "How often to flush metrics to --graphite-address")
+	annBenchmarkCommand.PersistentFlags().BoolVar(&globalConfig.CollectServerMetrics,
+		"collect-server-metrics", false, "Sample server-side HNSW insert queue depth, tombstones, object count, and LSM segment count for the duration of the run and attach them to the results")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.PrometheusQueryURL,
+		"prometheus-query-url", "", "Prometheus server URL to query for --collect-server-metrics (default: scrape Weaviate's own :2112/metrics endpoint directly)")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.ServerMetricsIntervalSec,
+		"server-metrics-interval-seconds", 5, "How often to sample server metrics for --collect-server-metrics")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.CheckpointFile,
+		"checkpoint-file", "", "Path to a local bolt file recording committed import ranges, so a crashed/interrupted import can resume (default disabled)")
+	annBenchmarkCommand.PersistentFlags().BoolVar(&globalConfig.Resume,
+		"resume", false, "Resume an import from --checkpoint-file instead of restarting from offset 0; refuses to recreate the schema if a checkpoint is found")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.DatasetURL,
+		"dataset-url", "", "Remote dataset to import from instead of --vectors, as file://, http(s)://, s3:// or gs:// (s3/gs require a public or pre-signed URL). The file is downloaded to --dataset-cache-dir first and opened as HDF5; .fvecs/.bvecs URLs are not downloaded and fail to open in this command")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.DatasetCacheDir,
+		"dataset-cache-dir", "", "Directory to cache a downloaded --dataset-url in (default: the OS temp dir)")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.DatasetPrefetch,
+		"dataset-prefetch", 8, "Number of concurrent range requests to keep in flight while reading a remote --dataset-url")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.CPUProfile,
+		"cpuProfile", "", "Directory to write a pprof CPU profile per phase into, named <runID>-<phase>.cpu.pprof (default disabled)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.MemProfile,
+		"memProfile", "", "Directory to write a pprof heap profile into at the end of each phase, named <runID>-<phase>.heap.pprof (default disabled)")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.MemProfileRate,
+		"memProfileRate", 0, "Set runtime.MemProfileRate for --memProfile (default: leave the runtime default in place)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.Trace,
+		"trace", "", "Directory to write a runtime/trace per phase into, named <runID>-<phase>.trace (default disabled)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.Baseline,
+		"baseline", "", "Path to an earlier run's ./results/<runID>.json to compare this run against, joined by (dataset, ef, api, limit, indexType) (default disabled)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.FailOnRegression,
+		"failOnRegression", "", "Comma-separated per-metric thresholds to enforce against --baseline, e.g. \"qps:-5%,recall:-0.5pp,p99:+10%\" (qps/mean/p99/recall/heap, %% for relative change, pp for recall's absolute percentage points); exits non-zero if any (ef,dataset) pair crosses its threshold")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.ParallelArray,
+		"parallelArray", "", "Comma-separated list of --parallel values to sweep, e.g. \"8,16,32\" (default: just --parallel)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.LimitArray,
+		"limitArray", "", "Comma-separated list of --limit values to sweep, e.g. \"10,100\" (default: just --limit)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.BatchSizeArray,
+		"batchSizeArray", "", "Comma-separated list of --batchSize values to sweep; requires --sweepRebuild since batch size only takes effect at import time (default: just --batchSize)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.MaxConnectionsArray,
+		"maxConnectionsArray", "", "Comma-separated list of --maxConnections values to sweep; requires --sweepRebuild since it's an HNSW build-time parameter (default: just --maxConnections)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.EfConstructionArray,
+		"efConstructionArray", "", "Comma-separated list of --efConstruction values to sweep; requires --sweepRebuild since it's an HNSW build-time parameter (default: just --efConstruction)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.RescoreLimitArray,
+		"rescoreLimitArray", "", "Comma-separated list of --rescoreLimit values to sweep; requires --sweepRebuild since compression rescore limit is fixed at import time (default: just --rescoreLimit)")
+	annBenchmarkCommand.PersistentFlags().BoolVar(&globalConfig.SweepRebuild,
+		"sweepRebuild", false, "Re-create the schema and re-import once per combination of *Array build-time parameters (batchSizeArray/maxConnectionsArray/efConstructionArray/rescoreLimitArray); without it, only the first combination runs and the rest are skipped with a warning")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.GrpcCompression,
+		"grpcCompression", "none", "gRPC client-side compression for import and query traffic, one of [none, gzip]")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.GrpcMaxRecvMsgSize,
+		"grpcMaxRecvMsgSize", 0, "Max gRPC response message size in bytes for import and query connections (default: grpc-go's built-in default)")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.GrpcInitialWindowSize,
+		"grpcInitialWindowSize", 0, "gRPC stream-level flow-control initial window size in bytes (default: grpc-go's built-in default)")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.GrpcInitialConnWindowSize,
+		"grpcInitialConnWindowSize", 0, "gRPC connection-level flow-control initial window size in bytes (default: grpc-go's built-in default)")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.GrpcWriteBufferSize,
+		"grpcWriteBufferSize", 0, "gRPC per-connection write buffer size in bytes (default: grpc-go's built-in default)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.QueryOrder,
+		"queryOrder", "sequential", "Order to walk the test set in, one of [sequential, shuffled, random]; shuffled permutes once per --querySeed and cycles it, random samples with replacement")
+	annBenchmarkCommand.PersistentFlags().Int64Var(&globalConfig.QuerySeed,
+		"querySeed", 0, "Seed for --queryOrder=shuffled/random, so a given seed always issues the same query sequence")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.QueryRepeat,
+		"queryRepeat", 0, "Issue exactly this many queries per ef, cycling the test set if needed, instead of one full pass (default disabled: one pass, or --queryDuration if set)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.GrpcCAFile,
+		"grpcCAFile", "", "PEM-encoded CA bundle to verify the gRPC server certificate against when --httpScheme=https (default: the system CA pool)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.GrpcClientCertFile,
+		"grpcClientCertFile", "", "PEM-encoded client certificate for mTLS, requires --grpcClientKeyFile (default disabled)")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.GrpcClientKeyFile,
+		"grpcClientKeyFile", "", "PEM-encoded client private key for mTLS, requires --grpcClientCertFile (default disabled)")
+	annBenchmarkCommand.PersistentFlags().BoolVar(&globalConfig.GrpcTLSSkipVerify,
+		"grpcTLSSkipVerify", false, "Skip verifying the gRPC server certificate when --httpScheme=https; mutually exclusive with --grpcCAFile, which it would otherwise silently ignore")
+	annBenchmarkCommand.PersistentFlags().StringVar(&globalConfig.GrpcAuthToken,
+		"grpcAuthToken", "", "Bearer token sent as per-RPC credentials on the import and query gRPC connections, for clusters that enforce auth on their gRPC port (default disabled)")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.GrpcKeepaliveTimeSeconds,
+		"grpcKeepaliveTime", 0, "Send a gRPC keepalive ping after this many seconds of inactivity, so long imports/benchmarks don't lose an idle connection to a proxy or load balancer (default disabled)")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.GrpcKeepaliveTimeout,
+		"grpcKeepaliveTimeout", 20, "Seconds to wait for a keepalive ping ack before considering the connection dead (only applies when --grpcKeepaliveTime is set)")
+	annBenchmarkCommand.PersistentFlags().BoolVar(&globalConfig.GrpcKeepaliveNoStream,
+		"grpcKeepalivePermitWithoutStream", false, "Send keepalive pings even when there are no in-flight RPCs (only applies when --grpcKeepaliveTime is set)")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.GrpcRetryMaxAttempts,
+		"grpcRetryMaxAttempts", 0, "Max gRPC-level retry attempts for transient errors, on top of the manual retry loop already used for query RPCs (default disabled: rely on the query-level --max-retries)")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.GrpcRetryPerTryTimeoutSec,
+		"grpcRetryPerTryTimeout", 0, "Per-attempt timeout in seconds for --grpcRetryMaxAttempts (default: no per-attempt timeout beyond the RPC's own context deadline)")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.GrpcRetryBackoffBaseMs,
+		"grpcRetryBackoffBaseMs", 100, "Base exponential backoff in milliseconds between gRPC-level retry attempts")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.GrpcRetryBackoffMaxMs,
+		"grpcRetryBackoffMaxMs", 0, "Cap on the exponential backoff between gRPC-level retry attempts (default disabled: backoff grows unbounded with attempt count)")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.ImportWorkers,
+		"importWorkers", 8, "Number of concurrent import worker goroutines dialing gRPC and writing batches (default 8)")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.ImportQueueDepth,
+		"importQueueDepth", 10, "Size of the batch channel buffer between the dataset reader and the import workers; raise it to let reading run further ahead of slow writers (default 10)")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.WarmupQueries,
+		"warmupQueries", 0, "Number of throwaway queries to run against the test set before recording benchmark results, so Go runtime scheduling, HNSW cache population, and gRPC connection ramp-up don't pollute the reported percentiles (default 0, disabled)")
+	annBenchmarkCommand.PersistentFlags().IntVar(&globalConfig.WarmupDuration,
+		"warmupDuration", 0, "Instead of a fixed number of warmup queries, warm up for this many seconds before recording results (default 0, disabled)")
+}
+
+// buildQueryOrder returns, for a single benchmarkANN call, the sequence of
+// query indices into [0, total) to issue: "sequential" (the default) walks
+// them once and cycles if more than total are needed; "shuffled" permutes
+// them once with --querySeed and cycles that same permutation, so a given
+// seed always issues the same sequence; "random" samples with replacement
+// so long soak runs aren't dominated by every query having already been
+// served once (and thus cached) by the time the run ends.
+func buildQueryOrder(cfg Config, total int) []int {
+	count := total
+	if cfg.QueryRepeat > 0 {
+		count = cfg.QueryRepeat
+	}
+
+	order := make([]int, count)
+
+	switch cfg.QueryOrder {
+	case "", "sequential":
+		for i := range order {
+			order[i] = i % total
+		}
+	case "shuffled":
+		perm := rand.New(rand.NewSource(cfg.QuerySeed)).Perm(total)
+		for i := range order {
+			order[i] = perm[i%total]
+		}
+	case "random":
+		rng := rand.New(rand.NewSource(cfg.QuerySeed))
+		for i := range order {
+			order[i] = rng.Intn(total)
+		}
+	default:
+		log.Fatalf("Unsupported --queryOrder %q, must be one of [sequential, shuffled, random]", cfg.QueryOrder)
+	}
+
+	return order
 }
 
 func benchmarkANN(cfg Config, queries Queries, neighbors Neighbors, filters []int) Results {
-	cfg.Queries = len(queries)
+	order := buildQueryOrder(cfg, len(queries))
+	cfg.Queries = len(order)
 
 	i := 0
 	return benchmark(cfg, func(className string) QueryWithNeighbors {
 		defer func() { i++ }()
 
+		idx := order[i]
+
 		tenant := ""
 		if cfg.NumTenants > 0 {
 			tenant = fmt.Sprint(rand.Intn(cfg.NumTenants))
 		}
 		filter := -1
 		if cfg.Filter {
-			filter = filters[i]
+			filter = filters[idx]
 		}
 
 		return QueryWithNeighbors{
-			Query:     nearVectorQueryGrpc(cfg, queries[i], cfg.Limit, tenant, filter),
-			Neighbors: neighbors[i],
+			Query:     nearVectorQueryGrpc(cfg, queries[idx], cfg.Limit, tenant, filter),
+			Neighbors: neighbors[idx],
 		}
 
 	})
@@ -1268,14 +1996,31 @@ type sampledResults struct {
 func benchmarkANNDuration(cfg Config, queries Queries, neighbors Neighbors, filters []int) Results {
 	cfg.Queries = len(queries)
 
+	return benchmarkDuration(cfg, func(iteration int) Results {
+		repCfg := cfg
+		repCfg.QuerySeed = cfg.QuerySeed + int64(iteration)
+		return benchmarkANN(repCfg, queries, neighbors, filters)
+	})
+}
+
+// benchmarkDuration is the generic --queryDuration driver shared by every
+// duration-based benchmark command: it calls runOnce back-to-back, passing
+// the 0-based iteration number, until cfg.QueryDuration seconds have
+// elapsed, then folds the per-iteration Results into a single median result
+// via sampledResults - the same approach benchmarkANNDuration and
+// benchmarkNearVectorDuration already used before being rewritten on top of
+// this shared driver. The per-iteration Results are also kept, unreduced, on
+// the returned Results.Samples, so warmup effects and QPS drift over a long
+// soak run remain visible rather than being smoothed away by the median.
+func benchmarkDuration(cfg Config, runOnce func(iteration int) Results) Results {
 	var samples sampledResults
 
 	startTime := time.Now()
 
 	var results Results
-
+	iterations := 0
 	for time.Since(startTime) < time.Duration(cfg.QueryDuration)*time.Second {
-		results = benchmarkANN(cfg, queries, neighbors, filters)
+		results = runOnce(iterations)
 		samples.Min = append(samples.Min, results.Min)
 		samples.Max = append(samples.Max, results.Max)
 		samples.Mean = append(samples.Mean, results.Mean)
@@ -1283,6 +2028,7 @@ func benchmarkANNDuration(cfg Config, queries Queries, neighbors Neighbors, filt
 		samples.QueriesPerSecond = append(samples.QueriesPerSecond, results.QueriesPerSecond)
 		samples.Recall = append(samples.Recall, results.Recall)
 		samples.Results = append(samples.Results, results)
+		iterations++
 	}
 
 	var medianResult Results
@@ -1299,13 +2045,16 @@ func benchmarkANNDuration(cfg Config, queries Queries, neighbors Neighbors, filt
 	medianResult.Failed = results.Failed
 	medianResult.Parallelization = cfg.Parallel
 	medianResult.Recall = median(samples.Recall)
+	medianResult.Samples = samples.Results
+
+	log.WithFields(log.Fields{"iterations": iterations}).Infof("Queried for %d seconds", cfg.QueryDuration)
 
 	return medianResult
 }
 
-func processChunk(chunk Batch, grpcClient *weaviategrpc.WeaviateClient, weaviateClient *weaviate.Client, cfg *Config, namedVector string, updatePercent float32) {
+func processChunk(chunk Batch, grpcClient *weaviategrpc.WeaviateClient, weaviateClient *weaviate.Client, cfg *Config, namedVector string, updatePercent float32, checkpoint *checkpointStore, checkpointKey string) {
 	if updatePercent > 0 && rand.Float32() < updatePercent {
 		deleteChunk(&chunk, weaviateClient, cfg)
 	}
-	writeChunk(&chunk, grpcClient, cfg, namedVector)
+	writeChunk(&chunk, grpcClient, cfg, namedVector, checkpoint, checkpointKey)
 }
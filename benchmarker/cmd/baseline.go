@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// baselineRecord decodes one entry of a ./results/<runID>.json file - the
+// same resultMap shape runQueries writes - for --baseline comparison. It
+// only pulls the fields the (dataset, ef, api, limit, indexType) join key
+// and the compared metrics need, not the full ResultsJSONBenchmark shape.
+type baselineRecord struct {
+	Dataset          string  `json:"dataset_file"`
+	IndexType        string  `json:"indexType"`
+	Api              string  `json:"api"`
+	Ef               int     `json:"ef"`
+	Limit            int     `json:"limit"`
+	QueriesPerSecond float64 `json:"qps"`
+	Mean             float64 `json:"meanLatency"`
+	P99Latency       float64 `json:"p99Latency"`
+	Recall           float64 `json:"recall"`
+	HeapInuseBytes   float64 `json:"heap_inuse_bytes"`
+}
+
+func (r baselineRecord) key() string {
+	return fmt.Sprintf("%s|%s|%s|%d|%d", r.Dataset, r.IndexType, r.Api, r.Ef, r.Limit)
+}
+
+// baselineMetric is one of the columns --baseline compares. pp reports the
+// delta in the metric's own units scaled by 100 (used for Recall, which is
+// already a 0-1 fraction, so this reads as percentage points); everything
+// else is reported as percent change relative to the baseline value.
+type baselineMetric struct {
+	name  string
+	pp    bool
+	value func(baselineRecord) float64
+}
+
+var baselineMetrics = []baselineMetric{
+	{name: "qps", value: func(r baselineRecord) float64 { return r.QueriesPerSecond }},
+	{name: "mean", value: func(r baselineRecord) float64 { return r.Mean }},
+	{name: "p99", value: func(r baselineRecord) float64 { return r.P99Latency }},
+	{name: "recall", pp: true, value: func(r baselineRecord) float64 { return r.Recall }},
+	{name: "heap", value: func(r baselineRecord) float64 { return r.HeapInuseBytes }},
+}
+
+// delta returns the change from base to cur in the unit metric's table
+// column reports: percentage points for recall, percent change otherwise.
+func (m baselineMetric) delta(cur, base baselineRecord) float64 {
+	curVal, baseVal := m.value(cur), m.value(base)
+	if m.pp {
+		return (curVal - baseVal) * 100
+	}
+	if baseVal == 0 {
+		return 0
+	}
+	return (curVal - baseVal) / baseVal * 100
+}
+
+// regressionThreshold is one --failOnRegression rule, e.g. "qps:-5%" (fail
+// if qps drops more than 5% relative to baseline) or "recall:-0.5pp" (fail
+// if recall drops more than 0.5 percentage points). The sign of value is
+// taken from the rule itself, so a rule never needs to know which direction
+// is "worse" for its metric.
+type regressionThreshold struct {
+	metric string
+	value  float64
+	pp     bool
+}
+
+func parseRegressionThresholds(spec string) ([]regressionThreshold, error) {
+	var out []regressionThreshold
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid --failOnRegression rule %q, expected metric:threshold", part)
+		}
+
+		metric := strings.TrimSpace(kv[0])
+		valStr := strings.TrimSpace(kv[1])
+
+		pp := false
+		switch {
+		case strings.HasSuffix(valStr, "%"):
+			valStr = strings.TrimSuffix(valStr, "%")
+		case strings.HasSuffix(valStr, "pp"):
+			valStr = strings.TrimSuffix(valStr, "pp")
+			pp = true
+		default:
+			return nil, fmt.Errorf("invalid --failOnRegression threshold %q, must end in %% or pp", kv[1])
+		}
+
+		value, err := strconv.ParseFloat(valStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --failOnRegression threshold %q: %w", kv[1], err)
+		}
+
+		out = append(out, regressionThreshold{metric: metric, value: value, pp: pp})
+	}
+	return out, nil
+}
+
+// breached reports whether delta (computed the same way baselineMetric.delta
+// does, for metrics matching th's pp/percent unit) is at least as bad as th.
+// The threshold's own sign marks which direction is a regression: negative
+// thresholds regress when delta falls at or below them, positive thresholds
+// regress when delta rises at or above them.
+func (th regressionThreshold) breached(delta float64) bool {
+	if th.value < 0 {
+		return delta <= th.value
+	}
+	return delta >= th.value
+}
+
+func loadBaselineRecords(path string) (map[string]baselineRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading --baseline file %q: %w", path, err)
+	}
+
+	var records []baselineRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("error decoding --baseline file %q: %w", path, err)
+	}
+
+	out := make(map[string]baselineRecord, len(records))
+	for _, r := range records {
+		out[r.key()] = r
+	}
+	return out, nil
+}
+
+// compareToBaseline loads cfg.Baseline, joins it against this run's own
+// results (already marshaled to the same shape by runQueries) on (dataset,
+// ef, api, limit, indexType), prints a delta table, and - if
+// cfg.FailOnRegression is set - exits non-zero when any joined pair crosses
+// its threshold. This mirrors the base/curPerf comparison step of gRPC's
+// benchmain, so a CI job can gate a Weaviate PR on it directly.
+func compareToBaseline(cfg *Config, results []map[string]interface{}) {
+	thresholds, err := parseRegressionThresholds(cfg.FailOnRegression)
+	if err != nil {
+		log.Fatalf("Error parsing --failOnRegression: %v", err)
+	}
+
+	baseline, err := loadBaselineRecords(cfg.Baseline)
+	if err != nil {
+		log.Fatalf("Error loading --baseline: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "DATASET\tINDEX\tAPI\tEF\tLIMIT\tQPS\tMEAN\tP99\tRECALL\tHEAP")
+
+	var regressed []string
+	for _, resultMap := range results {
+		data, err := json.Marshal(resultMap)
+		if err != nil {
+			log.Fatalf("Error re-marshaling result for baseline comparison: %v", err)
+		}
+
+		var cur baselineRecord
+		if err := json.Unmarshal(data, &cur); err != nil {
+			log.Fatalf("Error decoding result for baseline comparison: %v", err)
+		}
+
+		base, ok := baseline[cur.key()]
+		if !ok {
+			continue
+		}
+
+		deltas := make(map[string]float64, len(baselineMetrics))
+		cells := make([]string, 0, len(baselineMetrics))
+		for _, m := range baselineMetrics {
+			d := m.delta(cur, base)
+			deltas[m.name] = d
+			unit := "%"
+			if m.pp {
+				unit = "pp"
+			}
+			cells = append(cells, fmt.Sprintf("%+.2f%s", d, unit))
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			cur.Dataset, cur.IndexType, cur.Api, cur.Ef, cur.Limit,
+			cells[0], cells[1], cells[2], cells[3], cells[4])
+
+		for _, th := range thresholds {
+			d, ok := deltas[th.metric]
+			if !ok {
+				continue
+			}
+			if th.breached(d) {
+				regressed = append(regressed, fmt.Sprintf("%s/%s ef=%d: %s regressed %+.2f (threshold %+.2f)",
+					cur.Dataset, cur.Api, cur.Ef, th.metric, d, th.value))
+			}
+		}
+	}
+
+	w.Flush()
+
+	if len(regressed) > 0 {
+		log.Fatalf("Regression vs --baseline:\n%s", strings.Join(regressed, "\n"))
+	}
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,17 +11,24 @@ import (
 	"math/rand"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 
 	wv1 "github.com/weaviate/weaviate/grpc/generated/protocol/v1"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -31,8 +37,49 @@ type QueryWithNeighbors struct {
 	Neighbors []int
 }
 
-func processQueueHttp(queue []QueryWithNeighbors, cfg *Config, c *http.Client, m *sync.Mutex, times *[]time.Duration) {
+// cyclingIndexFn returns a function producing successive indices into a
+// collection of n queries, wrapping back to 0 once exhausted so a
+// --queryDuration run can keep issuing queries past the end of a finite
+// dataset instead of stalling. When shuffle is true, a fresh permutation is
+// drawn each time the cycle wraps, so a long soak run doesn't replay the
+// exact same sequence on every lap.
+func cyclingIndexFn(n int, shuffle bool) func() int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+
+	pos := 0
+	return func() int {
+		if pos >= n {
+			pos = 0
+			if shuffle {
+				rand.Shuffle(n, func(a, b int) { order[a], order[b] = order[b], order[a] })
+			}
+		}
+		idx := order[pos]
+		pos++
+		return idx
+	}
+}
+
+// PerQueryData holds the per-query outcomes recorded when cfg.RecordPerQuery
+// is set, keyed by metric so "benchmarker compare" can pair them up query for
+// query across two runs. LatencyNs is always populated when recording is on;
+// Recall and NDCG are only populated on the grpc path, since that's the only
+// one currently computing them.
+type PerQueryData struct {
+	LatencyNs []int64
+	Recall    []float64
+	NDCG      []float64
+}
+
+func processQueueHttp(ctx context.Context, queue []QueryWithNeighbors, cfg *Config, c *http.Client, m *sync.Mutex, times *[]time.Duration, timedOut *int, perQuery *PerQueryData, metrics *LiveMetrics, graphite *GraphiteReporter, progress *benchmarkProgress) {
 	for _, query := range queue {
+		if ctx.Err() != nil {
+			return
+		}
+
 		r := bytes.NewReader(query.Query)
 		before := time.Now()
 		var url string
@@ -42,8 +89,11 @@ func processQueueHttp(queue []QueryWithNeighbors, cfg *Config, c *http.Client, m
 		} else if cfg.API == "rest" {
 			url = fmt.Sprintf("%s/v1/objects/%s/_search", origin, cfg.ClassName)
 		}
-		req, err := http.NewRequest("POST", url, r)
+
+		queryCtx, cancel := context.WithTimeout(ctx, time.Duration(cfg.QueryTimeoutSeconds)*time.Second)
+		req, err := http.NewRequestWithContext(queryCtx, "POST", url, r)
 		if err != nil {
+			cancel()
 			fmt.Printf("ERROR: %v\n", err)
 			continue
 		}
@@ -54,9 +104,23 @@ func processQueueHttp(queue []QueryWithNeighbors, cfg *Config, c *http.Client, m
 			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", cfg.HttpAuth))
 		}
 
+		metrics.IncInFlight()
 		res, err := c.Do(req)
+		queryCtxErr := queryCtx.Err()
+		cancel()
+		metrics.DecInFlight()
 		if err != nil {
+			if queryCtxErr != nil {
+				m.Lock()
+				*timedOut++
+				m.Unlock()
+				metrics.ObserveQuery(cfg.API, "timeout", time.Since(before))
+				progress.recordQuery(time.Since(before))
+				continue
+			}
 			fmt.Printf("ERROR: %v\n", err)
+			metrics.ObserveQuery(cfg.API, "error", time.Since(before))
+			progress.recordQuery(time.Since(before))
 			continue
 		}
 		took := time.Since(before)
@@ -70,55 +134,151 @@ func processQueueHttp(queue []QueryWithNeighbors, cfg *Config, c *http.Client, m
 			if result["data"] != nil && result["errors"] == nil {
 				m.Lock()
 				*times = append(*times, took)
+				if perQuery != nil {
+					perQuery.LatencyNs = append(perQuery.LatencyNs, took.Nanoseconds())
+				}
 				m.Unlock()
+				metrics.ObserveQuery(cfg.API, "success", took)
+				graphite.RecordLatency(took)
 			} else {
 				fmt.Printf("GraphQL Error: %v\n", result)
+				metrics.ObserveQuery(cfg.API, "error", took)
 			}
 		} else {
 			if list, ok := result["objects"].([]interface{}); ok {
 				if len(list) > 0 {
 					m.Lock()
 					*times = append(*times, took)
+					if perQuery != nil {
+						perQuery.LatencyNs = append(perQuery.LatencyNs, took.Nanoseconds())
+					}
 					m.Unlock()
+					metrics.ObserveQuery(cfg.API, "success", took)
+					graphite.RecordLatency(took)
 				} else {
 					fmt.Printf("REST Error: %v\n", result)
+					metrics.ObserveQuery(cfg.API, "error", took)
 				}
 			} else {
 				fmt.Printf("REST Error: %v\n", result)
+				metrics.ObserveQuery(cfg.API, "error", took)
 			}
 		}
+		progress.recordQuery(took)
+	}
+}
+
+// defaultRetryableCodes is the set of gRPC status codes treated as
+// transient (and therefore worth retrying) when cfg.RetryableCodes is
+// left unset.
+var defaultRetryableCodes = map[codes.Code]bool{
+	codes.Unavailable:       true,
+	codes.DeadlineExceeded:  true,
+	codes.ResourceExhausted: true,
+}
+
+// parseRetryableCodes turns a comma-separated list of gRPC status code
+// names (e.g. "Unavailable,ResourceExhausted") into the set processQueueGrpc
+// retries on. An empty string falls back to defaultRetryableCodes.
+func parseRetryableCodes(s string) map[codes.Code]bool {
+	if strings.TrimSpace(s) == "" {
+		return defaultRetryableCodes
+	}
+
+	codeByName := make(map[string]codes.Code, len(defaultRetryableCodes))
+	for c := codes.Code(0); c <= codes.Unauthenticated; c++ {
+		codeByName[c.String()] = c
+	}
+
+	result := make(map[codes.Code]bool)
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if c, ok := codeByName[name]; ok {
+			result[c] = true
+		} else {
+			log.Warnf("unrecognized retryable grpc code %q, ignoring", name)
+		}
 	}
+	return result
 }
 
-func processQueueGrpc(queue []QueryWithNeighbors, cfg *Config, grpcConn *grpc.ClientConn, m *sync.Mutex, times *[]time.Duration, recall *[]float64, ndcg *[]float64) {
+func processQueueGrpc(runCtx context.Context, queue []QueryWithNeighbors, cfg *Config, grpcConn *grpc.ClientConn, m *sync.Mutex, times *[]time.Duration, recall *[]float64, ndcg *[]float64, timedOut *int, perQuery *PerQueryData, metrics *LiveMetrics, errorsByCode map[codes.Code]int, graphite *GraphiteReporter, progress *benchmarkProgress) {
 
 	grpcClient := wv1.NewWeaviateClient(grpcConn)
+	retryableCodes := parseRetryableCodes(cfg.RetryableCodes)
 
 	for _, query := range queue {
+		if runCtx.Err() != nil {
+			return
+		}
 
 		searchRequest := &wv1.SearchRequest{}
-		err := proto.Unmarshal(query.Query, searchRequest)
-		if err != nil {
-			log.Fatalf("Failed to unmarshal grpc query: %v", err)
+		if err := proto.Unmarshal(query.Query, searchRequest); err != nil {
+			log.Errorf("Failed to unmarshal grpc query: %v", err)
+			m.Lock()
+			errorsByCode[codes.Internal]++
+			m.Unlock()
+			continue
 		}
 
 		before := time.Now()
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
+		var searchReply *wv1.SearchReply
+		var err error
+		var took time.Duration
+		var timedOutThisQuery bool
 
-		if cfg.HttpAuth != "" {
-			md := metadata.Pairs(
-				"Authorization", fmt.Sprintf("Bearer %s", cfg.HttpAuth),
-			)
-			ctx = metadata.NewOutgoingContext(ctx, md)
+		for attempt := 0; ; attempt++ {
+			ctx, cancel := context.WithTimeout(runCtx, time.Duration(cfg.QueryTimeoutSeconds)*time.Second)
+
+			if cfg.HttpAuth != "" {
+				md := metadata.Pairs(
+					"Authorization", fmt.Sprintf("Bearer %s", cfg.HttpAuth),
+				)
+				ctx = metadata.NewOutgoingContext(ctx, md)
+			}
+
+			metrics.IncInFlight()
+			searchReply, err = grpcClient.Search(ctx, searchRequest)
+			ctxErr := ctx.Err()
+			cancel()
+			metrics.DecInFlight()
+			took = time.Since(before)
+
+			if err == nil {
+				break
+			}
+
+			if ctxErr != nil {
+				timedOutThisQuery = true
+				break
+			}
+
+			code := status.Code(err)
+			if attempt < cfg.MaxRetries && retryableCodes[code] {
+				metrics.ObserveGrpcError(code)
+				time.Sleep(time.Duration(attempt+1) * 100 * time.Millisecond)
+				continue
+			}
+
+			m.Lock()
+			errorsByCode[code]++
+			m.Unlock()
+			metrics.ObserveQuery(cfg.API, "error", took)
+			metrics.ObserveGrpcError(code)
+			break
 		}
 
-		searchReply, err := grpcClient.Search(ctx, searchRequest)
 		if err != nil {
-			log.Fatalf("Could not search with grpc: %v", err)
+			if timedOutThisQuery {
+				m.Lock()
+				*timedOut++
+				m.Unlock()
+				metrics.ObserveQuery(cfg.API, "timeout", took)
+			}
+			progress.recordQuery(took)
+			continue
 		}
-		took := time.Since(before)
 
 		if len(searchReply.GetResults()) != cfg.Limit {
 			fmt.Printf("Warning grpc got %d results, expected %d\n", len(searchReply.GetResults()), cfg.Limit)
@@ -138,7 +298,17 @@ func processQueueGrpc(queue []QueryWithNeighbors, cfg *Config, grpcConn *grpc.Cl
 		*times = append(*times, took)
 		*recall = append(*recall, recallQuery)
 		*ndcg = append(*ndcg, ndcgQuery)
+		if perQuery != nil {
+			perQuery.LatencyNs = append(perQuery.LatencyNs, took.Nanoseconds())
+			perQuery.Recall = append(perQuery.Recall, recallQuery)
+			perQuery.NDCG = append(perQuery.NDCG, ndcgQuery)
+		}
 		m.Unlock()
+		metrics.ObserveQuery(cfg.API, "success", took)
+		metrics.RecordRecall(recallQuery, ndcgQuery)
+		graphite.RecordLatency(took)
+		graphite.RecordRecall(recallQuery, ndcgQuery)
+		progress.recordQuery(took)
 	}
 }
 
@@ -173,17 +343,64 @@ func computeNDCG(trueRanking, predictedRanking []int, k int) float64 {
 }
 
 func benchmark(cfg Config, getQueryFn func(className string) QueryWithNeighbors) Results {
+	if cfg.QueryPattern == "open" {
+		return benchmarkOpenLoop(cfg, getQueryFn)
+	}
+
 	var times []time.Duration
 	var recall []float64
 	var ndcg []float64
+	var timedOut int
+	errorsByCode := make(map[codes.Code]int)
 	m := &sync.Mutex{}
 
+	var perQuery *PerQueryData
+	if cfg.RecordPerQuery {
+		perQuery = &PerQueryData{}
+	}
+
+	var metrics *LiveMetrics
+	if cfg.PrometheusConfig.ScrapePort > 0 {
+		metrics = NewLiveMetrics(prometheus.Labels{
+			"class": cfg.ClassName,
+			"api":   cfg.API,
+			"ef":    strconv.Itoa(cfg.CurrentEf),
+			"limit": strconv.Itoa(cfg.Limit),
+		})
+		srv := StartMetricsServer(cfg.PrometheusConfig.ScrapePort, metrics.Registry)
+		defer StopMetricsServer(srv)
+	}
+
+	graphite := NewGraphiteReporter(cfg.GraphiteConfig, map[string]string{"class": cfg.ClassName, "api": cfg.API})
+	graphite.Start()
+	defer graphite.Stop()
+
+	var serverMetrics *ServerMetricsCollector
+	if cfg.CollectServerMetrics {
+		serverMetrics = NewServerMetricsCollector(&cfg, time.Duration(cfg.ServerMetricsIntervalSec)*time.Second)
+		serverMetrics.Start()
+		defer serverMetrics.Stop()
+	}
+
+	queryTimeout := time.Duration(cfg.QueryTimeoutSeconds) * time.Second
+	if queryTimeout <= 0 {
+		queryTimeout = 30 * time.Second
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 120 * time.Second,
+	}
+
 	t := &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
-		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 120 * time.Second,
-		}).DialContext,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return newDeadlineConn(conn, queryTimeout), nil
+		},
 		MaxIdleConnsPerHost:   100,
 		MaxIdleConns:          100,
 		IdleConnTimeout:       90 * time.Second,
@@ -193,18 +410,41 @@ func benchmark(cfg Config, getQueryFn func(className string) QueryWithNeighbors)
 
 	httpClient := &http.Client{Transport: t}
 
-	httpOption := grpc.WithInsecure()
-
-	if cfg.HttpScheme == "https" {
-		creds := credentials.NewTLS(&tls.Config{
-			InsecureSkipVerify: true,
-		})
-		httpOption = grpc.WithTransportCredentials(creds)
+	// runCtx is always cancellable on its own, independent of any configured
+	// deadline, so the SIGINT/SIGTERM handler below can stop new queries from
+	// being issued without needing a deadline to be set at all.
+	runCtx, runCancel := context.WithCancel(context.Background())
+	defer runCancel()
+	if cfg.RunDeadlineSeconds > 0 {
+		var deadlineCancel context.CancelFunc
+		runCtx, deadlineCancel = context.WithTimeout(runCtx, time.Duration(cfg.RunDeadlineSeconds)*time.Second)
+		defer deadlineCancel()
 	}
 
+	var interrupted int32
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+	go func() {
+		if _, ok := <-sigCh; ok {
+			atomic.StoreInt32(&interrupted, 1)
+			log.Warn("received interrupt, no longer issuing new queries and draining in-flight requests...")
+			runCancel()
+		}
+	}()
+
+	httpOption := buildGrpcTransportOption(&cfg)
+
 	grpcCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	grpcConn, err := grpc.DialContext(grpcCtx, cfg.Origin, httpOption)
+	dialOptions := append([]grpc.DialOption{httpOption}, grpcTuningDialOptions(&cfg)...)
+	if perRPC := grpcPerRPCDialOption(&cfg); perRPC != nil {
+		dialOptions = append(dialOptions, perRPC)
+	}
+	if retryOpt := grpcRetryDialOption(&cfg); retryOpt != nil {
+		dialOptions = append(dialOptions, retryOpt)
+	}
+	grpcConn, err := grpc.DialContext(grpcCtx, cfg.Origin, dialOptions...)
 	if err != nil {
 		log.Fatalf("Did not connect: %v", err)
 	}
@@ -219,6 +459,8 @@ func benchmark(cfg Config, getQueryFn func(className string) QueryWithNeighbors)
 		queues[worker] = append(queues[worker], query)
 	}
 
+	progress := newBenchmarkProgress(cfg, cfg.Queries)
+
 	wg := &sync.WaitGroup{}
 	before := time.Now()
 	for _, queue := range queues {
@@ -226,17 +468,53 @@ func benchmark(cfg Config, getQueryFn func(className string) QueryWithNeighbors)
 		go func(queue []QueryWithNeighbors) {
 			defer wg.Done()
 			if cfg.API == "grpc" {
-				processQueueGrpc(queue, &cfg, grpcConn, m, &times, &recall, &ndcg)
+				processQueueGrpc(runCtx, queue, &cfg, grpcConn, m, &times, &recall, &ndcg, &timedOut, perQuery, metrics, errorsByCode, graphite, progress)
 			} else {
-				processQueueHttp(queue, &cfg, httpClient, m, &times)
+				processQueueHttp(runCtx, queue, &cfg, httpClient, m, &times, &timedOut, perQuery, metrics, graphite, progress)
 			}
 		}(queue)
 	}
-	wg.Wait()
 
-	return analyze(cfg, times, time.Since(before), recall, ndcg)
+	// A normal run's workers drain on their own once their queues empty. An
+	// interrupted run needs a bound here too, since a worker can be stuck
+	// waiting on a single slow in-flight request even after runCtx is
+	// cancelled (query RPCs only notice cancellation at their next
+	// opportunity, e.g. the grpc call returning).
+	drained := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-runCtx.Done():
+		select {
+		case <-drained:
+		case <-time.After(interruptDrainTimeout):
+			log.Warn("timed out waiting for in-flight queries to drain after interrupt, reporting partial results")
+		}
+	}
+
+	progress.stop()
+
+	if metrics != nil {
+		if err := pushRegistryToPrometheus(&cfg, metrics.Registry); err != nil {
+			log.WithError(err).Warn("Failed to push live metrics registry after run completed")
+		}
+	}
+
+	out := analyze(cfg, times, time.Since(before), recall, ndcg, timedOut, perQuery, errorsByCode, serverMetrics.Series())
+	out.Interrupted = atomic.LoadInt32(&interrupted) == 1
+	return out
 }
 
+// interruptDrainTimeout bounds how long benchmark() waits for in-flight
+// queries to finish after a SIGINT/SIGTERM, before giving up and reporting
+// whatever completed. It's generous relative to the default query timeout so
+// well-behaved in-flight requests get a real chance to finish normally.
+const interruptDrainTimeout = 30 * time.Second
+
 var targetPercentiles = []int{50, 90, 95, 98, 99}
 
 type Results struct {
@@ -253,12 +531,51 @@ type Results struct {
 	Parallelization   int
 	Recall            float64
 	NDCG              float64
+	Histogram         NativeHistogram
+	TimedOut          int
+	PerQuery          *PerQueryData
+	LatencyHistogram  HDRHistogram
+	// ResponseTimePercentiles/ResponseTimeHistogram are only populated by the
+	// open-loop load pattern (cfg.QueryPattern == "open"): ResponseTime is
+	// now - scheduledDispatchTime, i.e. it includes queueing delay, whereas
+	// the fields above measure service time alone (the RPC's own duration).
+	// That distinction is the whole point of open-loop generation - a
+	// saturated closed-loop benchmark simply stops generating load instead
+	// of queueing, which hides exactly the tail latency response time here
+	// surfaces.
+	ResponseTimePercentiles []time.Duration
+	ResponseTimeHistogram   HDRHistogram
+	// ErrorsByCode counts failed grpc queries by status code. It's only
+	// ever populated on the grpc path; processQueueHttp has no equivalent
+	// status-code taxonomy to classify errors by.
+	ErrorsByCode map[codes.Code]int
+	// ServerMetrics holds the time series ServerMetricsCollector sampled
+	// over the run (HNSW insert queue depth, tombstones, object count, LSM
+	// segment count), keyed the same way as serverMetricQueries. Empty
+	// unless cfg.CollectServerMetrics is set.
+	ServerMetrics map[string][]MetricSample
+	// PerTenant breaks latency/recall/NDCG down by ReplayRecord.Tenant.
+	// Only populated by benchmarkReplay; every other benchmark path queries
+	// a single class with no notion of tenant-scoped traffic.
+	PerTenant map[string]*TenantResults
+	// Interrupted is set when a SIGINT/SIGTERM stopped the run early. Total/
+	// Failed still reflect cfg.Queries, so the shortfall shows up as failed
+	// queries rather than a separate count.
+	Interrupted bool
+	// Samples holds the per-iteration Results a --queryDuration run folded
+	// into the median reported above, in iteration order, so warmup effects
+	// and QPS drift over a long soak run are still visible instead of being
+	// smoothed away. Only populated by benchmarkDuration; a fixed-query-count
+	// run has only one iteration and leaves this nil.
+	Samples []Results
 }
 
-func analyze(cfg Config, times []time.Duration, total time.Duration, recall []float64, ndcg []float64) Results {
-	out := Results{Min: math.MaxInt64, PercentilesLabels: targetPercentiles}
+func analyze(cfg Config, times []time.Duration, total time.Duration, recall []float64, ndcg []float64, timedOut int, perQuery *PerQueryData, errorsByCode map[codes.Code]int, serverMetrics map[string][]MetricSample) Results {
+	out := Results{Min: math.MaxInt64, PercentilesLabels: targetPercentiles, TimedOut: timedOut, PerQuery: perQuery, ErrorsByCode: errorsByCode, ServerMetrics: serverMetrics}
 	var sum time.Duration
 
+	hist := NewHDRHistogram(defaultHDRPrecisionBits, defaultHDRLowestTrackable, defaultHDRHighestTrackable)
+
 	for _, time := range times {
 		if time < out.Min {
 			out.Min = time
@@ -270,6 +587,7 @@ func analyze(cfg Config, times []time.Duration, total time.Duration, recall []fl
 
 		out.Successful++
 		sum += time
+		hist.Record(time)
 	}
 
 	var sumRecall float64
@@ -291,24 +609,115 @@ func analyze(cfg Config, times []time.Duration, total time.Duration, recall []fl
 	out.Recall = sumRecall / float64(len(recall))
 	out.NDCG = sumNDCG / float64(len(ndcg))
 
-	sort.Slice(times, func(a, b int) bool {
-		return times[a] < times[b]
-	})
+	out.Percentiles = make([]time.Duration, len(targetPercentiles))
+	for i, percentile := range targetPercentiles {
+		out.Percentiles[i] = hist.ValueAtPercentile(float64(percentile))
+	}
+	out.LatencyHistogram = *hist
+
+	out.Histogram = buildNativeHistogram(times)
+
+	return out
+}
+
+// nativeHistogramSchema controls the bucket resolution of the native
+// histogram below: each bucket boundary is base^index where
+// base = 2^(2^-schema). Schema 3 gives a base of 2^(1/8), matching the
+// default Prometheus client library uses for native histograms.
+const nativeHistogramSchema = 3
+
+// nativeHistogramZeroThreshold is the width of the zero bucket; latencies
+// at or below this are counted as zero rather than log-bucketed, since
+// log(x) is undefined at x=0 and unstable for very small x.
+const nativeHistogramZeroThreshold = 1e-9
+
+// HistogramSpan describes a run of consecutive, non-empty buckets in a
+// sparse native histogram. Offset is the gap (in bucket indices) since the
+// end of the previous span, and Length is the number of buckets the span
+// covers.
+type HistogramSpan struct {
+	Offset int32  `json:"offset"`
+	Length uint32 `json:"length"`
+}
+
+// NativeHistogram is a sparse, exponential-bucket histogram of query
+// latencies, modelled on Prometheus' native histogram representation.
+// Storing it alongside the existing mean/percentile summary lets
+// downstream analysis compute arbitrary quantiles without being locked
+// into whatever percentiles we happened to precompute.
+type NativeHistogram struct {
+	Schema         int32           `json:"schema"`
+	ZeroThreshold  float64         `json:"zero_threshold"`
+	ZeroCount      uint64          `json:"zero_count"`
+	PositiveSpans  []HistogramSpan `json:"positive_spans"`
+	PositiveDeltas []int64         `json:"positive_deltas"`
+	Sum            float64         `json:"sum"`
+	Count          uint64          `json:"count"`
+}
 
-	percentilePos := func(percentile int) int {
-		return int(float64(len(times)*percentile)/100) + 1
+// buildNativeHistogram buckets query latencies (in seconds) on a log scale
+// and encodes the non-empty buckets as spans of consecutive indices plus
+// delta-encoded counts, so that long runs of unobserved buckets between
+// them cost nothing to store.
+func buildNativeHistogram(times []time.Duration) NativeHistogram {
+	base := math.Pow(2, math.Pow(2, -nativeHistogramSchema))
+
+	counts := make(map[int]uint64)
+	var zeroCount uint64
+	var sum float64
+
+	for _, t := range times {
+		seconds := t.Seconds()
+		sum += seconds
+
+		if seconds <= nativeHistogramZeroThreshold {
+			zeroCount++
+			continue
+		}
+
+		index := int(math.Floor(math.Log(seconds) / math.Log(base)))
+		counts[index]++
 	}
 
-	out.Percentiles = make([]time.Duration, len(targetPercentiles))
-	for i, percentile := range targetPercentiles {
-		pos := percentilePos(percentile)
-		if pos >= len(times) {
-			pos = len(times) - 1
+	indices := make([]int, 0, len(counts))
+	for index := range counts {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+
+	var spans []HistogramSpan
+	var deltas []int64
+	prevIndex := 0
+	var prevCount int64
+
+	for i, index := range indices {
+		count := int64(counts[index])
+
+		switch {
+		case i == 0:
+			spans = append(spans, HistogramSpan{Offset: int32(index), Length: 1})
+			deltas = append(deltas, count)
+		case index == prevIndex+1:
+			spans[len(spans)-1].Length++
+			deltas = append(deltas, count-prevCount)
+		default:
+			spans = append(spans, HistogramSpan{Offset: int32(index - prevIndex - 1), Length: 1})
+			deltas = append(deltas, count-prevCount)
 		}
-		out.Percentiles[i] = times[pos]
+
+		prevIndex = index
+		prevCount = count
 	}
 
-	return out
+	return NativeHistogram{
+		Schema:         nativeHistogramSchema,
+		ZeroThreshold:  nativeHistogramZeroThreshold,
+		ZeroCount:      zeroCount,
+		PositiveSpans:  spans,
+		PositiveDeltas: deltas,
+		Sum:            sum,
+		Count:          uint64(len(times)),
+	}
 }
 
 func intersection(a, b []int) []int {
@@ -338,22 +747,81 @@ func (r Results) WriteTextTo(w io.Writer) (int64, error) {
 		)
 	}
 
+	if r.ResponseTimeHistogram.Count > 0 {
+		b.WriteString("Response time (includes queueing):\n")
+		for i, percentile := range targetPercentiles {
+			b.WriteString(
+				fmt.Sprintf("  p%d: %s\n", percentile, r.ResponseTimePercentiles[i]),
+			)
+		}
+	}
+
+	if len(r.ErrorsByCode) > 0 {
+		b.WriteString("Errors by code:\n")
+		codesSorted := make([]codes.Code, 0, len(r.ErrorsByCode))
+		for code := range r.ErrorsByCode {
+			codesSorted = append(codesSorted, code)
+		}
+		sort.Slice(codesSorted, func(i, j int) bool { return codesSorted[i] < codesSorted[j] })
+		for _, code := range codesSorted {
+			b.WriteString(fmt.Sprintf("  %s: %d\n", code, r.ErrorsByCode[code]))
+		}
+	}
+
+	if len(r.PerTenant) > 0 {
+		b.WriteString("Per tenant:\n")
+		tenantsSorted := make([]string, 0, len(r.PerTenant))
+		for tenant := range r.PerTenant {
+			tenantsSorted = append(tenantsSorted, tenant)
+		}
+		sort.Strings(tenantsSorted)
+		for _, tenant := range tenantsSorted {
+			t := r.PerTenant[tenant]
+			b.WriteString(fmt.Sprintf("  %s: total=%d mean=%s recall=%f ndcg=%f\n", tenant, t.Total, t.Mean, t.Recall, t.NDCG))
+		}
+	}
+
 	n, err := w.Write([]byte(fmt.Sprintf(
-		"Results\nSuccessful: %d\nMin: %s\nMean: %s\n%sTook: %s\nQPS: %f\nRecall: %f\n",
-		r.Successful, r.Min, r.Mean, b.String(), r.Took, r.QueriesPerSecond, r.Recall)))
+		"Results\nSuccessful: %d\nTimedOut: %d\nMin: %s\nMean: %s\n%sTook: %s\nQPS: %f\nRecall: %f\n",
+		r.Successful, r.TimedOut, r.Min, r.Mean, b.String(), r.Took, r.QueriesPerSecond, r.Recall)))
 	return int64(n), err
 }
 
 type resultsJSON struct {
-	Metadata           resultsJSONMetadata   `json:"metadata"`
-	Latencies          map[string]int64      `json:"latencies"`
-	LatenciesFormatted map[string]string     `json:"latenciesFormatted"`
-	Throughput         resultsJSONThroughput `json:"throughput"`
+	Metadata           resultsJSONMetadata       `json:"metadata"`
+	Latencies          map[string]int64          `json:"latencies"`
+	LatenciesFormatted map[string]string         `json:"latenciesFormatted"`
+	Throughput         resultsJSONThroughput     `json:"throughput"`
+	Histogram          NativeHistogram           `json:"histogram"`
+	PerQuery           *perQueryJSON             `json:"perQuery,omitempty"`
+	LatencyHistogram   HDRHistogram              `json:"latencyHistogram"`
+	ResponseTime       *responseTimeJSON         `json:"responseTime,omitempty"`
+	ErrorsByCode       map[string]int            `json:"errorsByCode,omitempty"`
+	ServerMetrics      map[string][]MetricSample `json:"serverMetrics,omitempty"`
+	PerTenant          map[string]*TenantResults `json:"perTenant,omitempty"`
+}
+
+// responseTimeJSON is only populated for open-loop runs (cfg.QueryPattern ==
+// "open"): it carries the coordinated-omission-corrected latencies, i.e.
+// including time spent queued rather than just time spent in the RPC.
+type responseTimeJSON struct {
+	Percentiles map[string]int64 `json:"percentiles"`
+	Histogram   HDRHistogram     `json:"histogram"`
+}
+
+// perQueryJSON is the --record-per-query payload: the raw, unaggregated
+// per-query arrays behind the summary stats above, so "benchmarker compare"
+// can pair them up query-for-query across two result files.
+type perQueryJSON struct {
+	LatencyNs []int64   `json:"latencyNs"`
+	Recall    []float64 `json:"recall,omitempty"`
+	NDCG      []float64 `json:"ndcg,omitempty"`
 }
 
 type resultsJSONMetadata struct {
 	Successful      int    `json:"successful"`
 	Failed          int    `json:"failed"`
+	TimedOut        int    `json:"timedOut"`
 	Total           int    `json:"total"`
 	Parallelization int    `json:"parallelization"`
 	Took            int64  `json:"took"`
@@ -370,6 +838,7 @@ func (r Results) WriteJSONTo(w io.Writer) (int, error) {
 			Successful:      r.Successful,
 			Total:           r.Total,
 			Failed:          r.Failed,
+			TimedOut:        r.TimedOut,
 			Parallelization: r.Parallelization,
 			Took:            int64(r.Took),
 			TookFormatted:   fmt.Sprint(r.Took),
@@ -385,6 +854,42 @@ func (r Results) WriteJSONTo(w io.Writer) (int, error) {
 		Throughput: resultsJSONThroughput{
 			QPS: r.QueriesPerSecond,
 		},
+		Histogram:        r.Histogram,
+		LatencyHistogram: r.LatencyHistogram,
+	}
+
+	if r.PerQuery != nil {
+		obj.PerQuery = &perQueryJSON{
+			LatencyNs: r.PerQuery.LatencyNs,
+			Recall:    r.PerQuery.Recall,
+			NDCG:      r.PerQuery.NDCG,
+		}
+	}
+
+	if r.ResponseTimeHistogram.Count > 0 {
+		percentiles := make(map[string]int64, len(targetPercentiles))
+		for i, percentile := range targetPercentiles {
+			percentiles[fmt.Sprintf("p%d", percentile)] = int64(r.ResponseTimePercentiles[i])
+		}
+		obj.ResponseTime = &responseTimeJSON{
+			Percentiles: percentiles,
+			Histogram:   r.ResponseTimeHistogram,
+		}
+	}
+
+	if len(r.ErrorsByCode) > 0 {
+		obj.ErrorsByCode = make(map[string]int, len(r.ErrorsByCode))
+		for code, count := range r.ErrorsByCode {
+			obj.ErrorsByCode[code.String()] = count
+		}
+	}
+
+	if len(r.ServerMetrics) > 0 {
+		obj.ServerMetrics = r.ServerMetrics
+	}
+
+	if len(r.PerTenant) > 0 {
+		obj.PerTenant = r.PerTenant
 	}
 
 	for i, percentile := range targetPercentiles {
@@ -87,7 +87,7 @@ func TestAnalyzer(t *testing.T) {
 	ndcg := []float64{}
 
 	t.Run("check analyze accuracy", func(t *testing.T) {
-		results := analyze(c, durations, totalTime, recall, ndcg)
+		results := analyze(c, durations, totalTime, recall, ndcg, 0, nil, nil, nil)
 
 		require.Equal(t, 10, results.Total)
 		require.Equal(t, 3, results.Failed)
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.etcd.io/bbolt"
+)
+
+// checkpointStore persists import progress to a local bolt file, keyed by
+// (dataset file, class name, tenant), so a long hdf5/fvecs import can resume
+// after a crash, OOM, or Ctrl-C instead of restarting from offset 0. A nil
+// *checkpointStore behaves as "checkpointing disabled", matching the
+// LiveMetrics/GraphiteReporter nil-receiver convention elsewhere in this
+// package, so call sites never need to branch on whether --checkpoint-file
+// was set.
+type checkpointStore struct {
+	db *bbolt.DB
+}
+
+const (
+	checkpointCommittedBucket = "committed"
+	checkpointInFlightBucket  = "inflight"
+	checkpointFailedBucket    = "failed"
+)
+
+// openCheckpointStore opens (creating if necessary) the bolt file at path.
+func openCheckpointStore(path string) (*checkpointStore, error) {
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: 10 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error opening checkpoint file %s: %w", path, err)
+	}
+	return &checkpointStore{db: db}, nil
+}
+
+func (s *checkpointStore) Close() error {
+	if s == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// checkpointKey identifies one (dataset, class, tenant) import stream within
+// a shared checkpoint file.
+func checkpointKey(cfg *Config) string {
+	return fmt.Sprintf("%s|%s|%s", cfg.BenchmarkFile, cfg.ClassName, cfg.Tenant)
+}
+
+func rangeKey(start int) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(start))
+	return buf
+}
+
+func rangeVal(end int) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(end))
+	return buf
+}
+
+func checkpointBucket(tx *bbolt.Tx, key, name string) (*bbolt.Bucket, error) {
+	root, err := tx.CreateBucketIfNotExists([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return root.CreateBucketIfNotExists([]byte(name))
+}
+
+// reserve records [start, end) as in-flight before a BatchObjects call is
+// sent, so a crash mid-request shows up as an in-flight, not committed,
+// range in the next summary.
+func (s *checkpointStore) reserve(key string, start, end int) error {
+	if s == nil {
+		return nil
+	}
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := checkpointBucket(tx, key, checkpointInFlightBucket)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(rangeKey(start), rangeVal(end))
+	})
+}
+
+// commit marks [start, end) committed and clears it from in-flight. bbolt
+// fsyncs the file at the end of every Update transaction, so the range is
+// durable once this returns without error.
+func (s *checkpointStore) commit(key string, start, end int) error {
+	if s == nil {
+		return nil
+	}
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		inFlight, err := checkpointBucket(tx, key, checkpointInFlightBucket)
+		if err != nil {
+			return err
+		}
+		if err := inFlight.Delete(rangeKey(start)); err != nil {
+			return err
+		}
+
+		committed, err := checkpointBucket(tx, key, checkpointCommittedBucket)
+		if err != nil {
+			return err
+		}
+		return committed.Put(rangeKey(start), rangeVal(end))
+	})
+}
+
+// fail marks [start, end) failed and clears it from in-flight, so a retry
+// pass can find it via summary() and target just that range with
+// deleteUuidRange followed by a re-import.
+func (s *checkpointStore) fail(key string, start, end int) error {
+	if s == nil {
+		return nil
+	}
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		inFlight, err := checkpointBucket(tx, key, checkpointInFlightBucket)
+		if err != nil {
+			return err
+		}
+		if err := inFlight.Delete(rangeKey(start)); err != nil {
+			return err
+		}
+
+		failed, err := checkpointBucket(tx, key, checkpointFailedBucket)
+		if err != nil {
+			return err
+		}
+		return failed.Put(rangeKey(start), rangeVal(end))
+	})
+}
+
+// resumeOffset returns the row offset to resume streaming from: the end of
+// the longest unbroken run of committed ranges starting at 0. A gap left by
+// a crash (an in-flight or failed range with later committed ranges past
+// it) is intentionally re-imported rather than skipped, since writes are
+// idempotent on UUID.
+func (s *checkpointStore) resumeOffset(key string) (int, error) {
+	if s == nil {
+		return 0, nil
+	}
+
+	var next int
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		root := tx.Bucket([]byte(key))
+		if root == nil {
+			return nil
+		}
+		committed := root.Bucket([]byte(checkpointCommittedBucket))
+		if committed == nil {
+			return nil
+		}
+
+		type span struct{ start, end int }
+		var spans []span
+		err := committed.ForEach(func(k, v []byte) error {
+			spans = append(spans, span{
+				start: int(binary.BigEndian.Uint64(k)),
+				end:   int(binary.BigEndian.Uint64(v)),
+			})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+		offset := 0
+		for _, sp := range spans {
+			if sp.start > offset {
+				break
+			}
+			if sp.end > offset {
+				offset = sp.end
+			}
+		}
+		next = offset
+		return nil
+	})
+
+	return next, err
+}
+
+// checkpointHasProgress reports whether path already has a non-zero resume
+// offset recorded for cfg's (dataset, class, tenant), used to decide
+// whether --resume should skip schema recreation.
+func checkpointHasProgress(cfg *Config) (bool, error) {
+	if cfg.CheckpointFile == "" {
+		return false, nil
+	}
+
+	store, err := openCheckpointStore(cfg.CheckpointFile)
+	if err != nil {
+		return false, err
+	}
+	defer store.Close()
+
+	offset, err := store.resumeOffset(checkpointKey(cfg))
+	if err != nil {
+		return false, err
+	}
+	return offset > 0, nil
+}
+
+// checkpointSummary reports the three disjoint categories a reserved range
+// can end up in, for the periodic progress log an operator watches during a
+// long import.
+type checkpointSummary struct {
+	CommittedRows  int
+	InFlightRanges int
+	FailedRanges   int
+}
+
+func (s *checkpointStore) summary(key string) (checkpointSummary, error) {
+	var out checkpointSummary
+	if s == nil {
+		return out, nil
+	}
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		root := tx.Bucket([]byte(key))
+		if root == nil {
+			return nil
+		}
+
+		if committed := root.Bucket([]byte(checkpointCommittedBucket)); committed != nil {
+			err := committed.ForEach(func(k, v []byte) error {
+				out.CommittedRows += int(binary.BigEndian.Uint64(v)) - int(binary.BigEndian.Uint64(k))
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if inFlight := root.Bucket([]byte(checkpointInFlightBucket)); inFlight != nil {
+			out.InFlightRanges = inFlight.Stats().KeyN
+		}
+		if failed := root.Bucket([]byte(checkpointFailedBucket)); failed != nil {
+			out.FailedRanges = failed.Stats().KeyN
+		}
+
+		return nil
+	})
+
+	return out, err
+}
+
+// logCheckpointSummary logs s's progress every period until stop is closed,
+// the resumable-import counterpart to benchmarkProgress's render loop.
+func logCheckpointSummary(s *checkpointStore, key string, period time.Duration, stop <-chan struct{}) {
+	if s == nil {
+		return
+	}
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			summary, err := s.summary(key)
+			if err != nil {
+				log.Warnf("Error reading checkpoint summary: %v", err)
+				continue
+			}
+			log.WithFields(log.Fields{
+				"committedRows":  summary.CommittedRows,
+				"inFlightRanges": summary.InFlightRanges,
+				"failedRanges":   summary.FailedRanges,
+			}).Info("Checkpoint progress")
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// comparisonFile is the subset of resultsJSON that compare needs out of a
+// file written by Results.WriteJSONTo: the per-query arrays recorded behind
+// --record-per-query.
+type comparisonFile struct {
+	Metadata resultsJSONMetadata `json:"metadata"`
+	PerQuery *perQueryJSON       `json:"perQuery"`
+}
+
+var compareJSONOutput bool
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <result-a.json> <result-b.json>",
+	Short: "Compare two ann-benchmark JSON result files for statistically significant differences",
+	Long: `compare loads two JSON result files written by "ann-benchmark" with
+--record-per-query enabled, and runs paired statistical tests on their
+per-query latency, recall and NDCG arrays: a two-sided Wilcoxon signed-rank
+test on latency and NDCG deltas, a McNemar test on recall hit/miss outcomes,
+and bootstrap 95% confidence intervals on the mean delta of each metric.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		a, err := loadComparisonFile(args[0])
+		if err != nil {
+			log.Fatalf("Error loading %q: %v", args[0], err)
+		}
+
+		b, err := loadComparisonFile(args[1])
+		if err != nil {
+			log.Fatalf("Error loading %q: %v", args[1], err)
+		}
+
+		verdict, err := compareResults(a, b)
+		if err != nil {
+			log.Fatalf("Error comparing results: %v", err)
+		}
+
+		if compareJSONOutput {
+			data, err := json.MarshalIndent(verdict, "", "  ")
+			if err != nil {
+				log.Fatalf("Error marshaling verdict: %v", err)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
+		printComparisonTable(os.Stdout, verdict)
+	},
+}
+
+func initCompare() {
+	rootCmd.AddCommand(compareCmd)
+
+	compareCmd.Flags().BoolVar(&compareJSONOutput, "json", false,
+		"Emit the comparison verdict as machine-readable JSON instead of a text table")
+}
+
+func loadComparisonFile(path string) (*comparisonFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out comparisonFile
+	if err := json.NewDecoder(f).Decode(&out); err != nil {
+		return nil, fmt.Errorf("error decoding json: %w", err)
+	}
+
+	if out.PerQuery == nil {
+		return nil, fmt.Errorf("no per-query data found, re-run ann-benchmark with --record-per-query")
+	}
+
+	return &out, nil
+}
+
+const significanceAlpha = 0.05
+const bootstrapIterations = 5000
+
+// metricVerdict is one metric's paired comparison between run A and run B.
+type metricVerdict struct {
+	MeanA       float64 `json:"meanA"`
+	MeanB       float64 `json:"meanB"`
+	MeanDelta   float64 `json:"meanDelta"`
+	CILow       float64 `json:"ciLow95"`
+	CIHigh      float64 `json:"ciHigh95"`
+	Test        string  `json:"test"`
+	Statistic   float64 `json:"statistic"`
+	PValue      float64 `json:"pValue"`
+	Significant bool    `json:"significant"`
+}
+
+type comparisonVerdict struct {
+	Queries int           `json:"queries"`
+	Latency metricVerdict `json:"latency"`
+	Recall  metricVerdict `json:"recall"`
+	NDCG    metricVerdict `json:"ndcg,omitempty"`
+}
+
+// compareResults runs the paired tests described in the compareCmd help text
+// against two result files' per-query arrays. a and b must have the same
+// number of queries to be paired up index by index.
+func compareResults(a, b *comparisonFile) (*comparisonVerdict, error) {
+	n := len(a.PerQuery.LatencyNs)
+	if n != len(b.PerQuery.LatencyNs) {
+		return nil, fmt.Errorf("per-query latency arrays have different lengths (%d vs %d); both runs must use the same --queries count to be paired",
+			n, len(b.PerQuery.LatencyNs))
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("no per-query latencies recorded")
+	}
+
+	verdict := &comparisonVerdict{
+		Queries: n,
+		Latency: buildMetricVerdict(toFloat64(a.PerQuery.LatencyNs), toFloat64(b.PerQuery.LatencyNs), "wilcoxon", wilcoxonSignedRank),
+	}
+
+	if len(a.PerQuery.Recall) == n && len(b.PerQuery.Recall) == n {
+		verdict.Recall = buildMetricVerdict(a.PerQuery.Recall, b.PerQuery.Recall, "mcnemar", mcNemarTest)
+	}
+
+	if len(a.PerQuery.NDCG) == n && len(b.PerQuery.NDCG) == n {
+		verdict.NDCG = buildMetricVerdict(a.PerQuery.NDCG, b.PerQuery.NDCG, "wilcoxon", wilcoxonSignedRank)
+	}
+
+	return verdict, nil
+}
+
+func buildMetricVerdict(a, b []float64, testName string, test func(a, b []float64) (statistic, pValue float64)) metricVerdict {
+	statistic, pValue := test(a, b)
+	ciLow, ciHigh := bootstrapMeanDeltaCI(a, b, bootstrapIterations)
+
+	return metricVerdict{
+		MeanA:       mean(a),
+		MeanB:       mean(b),
+		MeanDelta:   mean(b) - mean(a),
+		CILow:       ciLow,
+		CIHigh:      ciHigh,
+		Test:        testName,
+		Statistic:   statistic,
+		PValue:      pValue,
+		Significant: pValue < significanceAlpha,
+	}
+}
+
+func toFloat64(ns []int64) []float64 {
+	out := make([]float64, len(ns))
+	for i, n := range ns {
+		out[i] = float64(n)
+	}
+	return out
+}
+
+func mean(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+// wilcoxonSignedRank runs a two-sided Wilcoxon signed-rank test on the paired
+// differences b[i]-a[i], using the normal approximation for the p-value
+// (accurate for the query counts ann-benchmark runs typically produce, and
+// avoids pulling in an exact-distribution table).
+func wilcoxonSignedRank(a, b []float64) (statistic, pValue float64) {
+	diffs := make([]rankable, 0, len(a))
+	for i := range a {
+		d := b[i] - a[i]
+		if d != 0 {
+			diffs = append(diffs, rankable{diff: d, abs: math.Abs(d)})
+		}
+	}
+
+	n := len(diffs)
+	if n == 0 {
+		return 0, 1
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].abs < diffs[j].abs })
+
+	ranks := assignRanks(diffs)
+
+	var wPos, wNeg float64
+	for i, d := range diffs {
+		if d.diff > 0 {
+			wPos += ranks[i]
+		} else {
+			wNeg += ranks[i]
+		}
+	}
+
+	w := math.Min(wPos, wNeg)
+	meanW := float64(n*(n+1)) / 4
+	varW := float64(n*(n+1)*(2*n+1)) / 24
+	if varW == 0 {
+		return w, 1
+	}
+
+	z := (w - meanW) / math.Sqrt(varW)
+	return w, 2 * (1 - normalCDF(math.Abs(z)))
+}
+
+// rankable is a signed paired difference awaiting a Wilcoxon rank.
+type rankable struct {
+	diff float64
+	abs  float64
+}
+
+// assignRanks ranks a slice already sorted ascending by abs, averaging ranks
+// across ties the way Wilcoxon's test requires.
+func assignRanks(diffs []rankable) []float64 {
+	ranks := make([]float64, len(diffs))
+
+	i := 0
+	for i < len(diffs) {
+		j := i
+		for j < len(diffs) && diffs[j].abs == diffs[i].abs {
+			j++
+		}
+		avgRank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			ranks[k] = avgRank
+		}
+		i = j
+	}
+
+	return ranks
+}
+
+// mcNemarTest runs a continuity-corrected McNemar test on paired recall
+// outcomes, treating each query as a hit (top-k contained at least one true
+// neighbor, recall > 0) or a miss, the same binary framing the task asked
+// for. Only the queries where the two runs disagree (a hit, b miss or vice
+// versa) carry any information, so the test statistic is built from those
+// discordant pairs alone.
+func mcNemarTest(a, b []float64) (statistic, pValue float64) {
+	var n01, n10 int // a miss/b hit, a hit/b miss
+	for i := range a {
+		hitA := a[i] > 0
+		hitB := b[i] > 0
+		switch {
+		case !hitA && hitB:
+			n01++
+		case hitA && !hitB:
+			n10++
+		}
+	}
+
+	discordant := n01 + n10
+	if discordant == 0 {
+		return 0, 1
+	}
+
+	statistic = math.Pow(math.Abs(float64(n01-n10))-1, 2) / float64(discordant)
+	// statistic ~ chi-squared with 1 degree of freedom under H0.
+	pValue = math.Erfc(math.Sqrt(statistic / 2))
+	return statistic, pValue
+}
+
+func normalCDF(z float64) float64 {
+	return 0.5 * (1 + math.Erf(z/math.Sqrt2))
+}
+
+// bootstrapMeanDeltaCI resamples the n paired (a[i], b[i]) observations with
+// replacement `iterations` times and returns the 2.5th/97.5th percentile of
+// the resampled mean(b)-mean(a), i.e. a 95% confidence interval on the mean
+// delta that doesn't assume the deltas are normally distributed.
+func bootstrapMeanDeltaCI(a, b []float64, iterations int) (low, high float64) {
+	n := len(a)
+	if n == 0 {
+		return 0, 0
+	}
+
+	deltas := make([]float64, n)
+	for i := range a {
+		deltas[i] = b[i] - a[i]
+	}
+
+	means := make([]float64, iterations)
+	for iter := 0; iter < iterations; iter++ {
+		var sum float64
+		for i := 0; i < n; i++ {
+			sum += deltas[rand.Intn(n)]
+		}
+		means[iter] = sum / float64(n)
+	}
+
+	sort.Float64s(means)
+	low = means[int(0.025*float64(iterations))]
+	high = means[int(0.975*float64(iterations))-1]
+	return low, high
+}
+
+func printComparisonTable(w io.Writer, v *comparisonVerdict) {
+	fmt.Fprintf(w, "Comparison (%d paired queries)\n", v.Queries)
+	fmt.Fprintf(w, "%-10s %-10s %14s %14s %14s %24s %10s %10s %12s\n",
+		"metric", "test", "mean A", "mean B", "delta", "95% CI", "stat", "p-value", "significant")
+	printMetricRow(w, "latency_ns", v.Latency)
+	printMetricRow(w, "recall", v.Recall)
+	if v.NDCG.Test != "" {
+		printMetricRow(w, "ndcg", v.NDCG)
+	}
+}
+
+func printMetricRow(w io.Writer, name string, m metricVerdict) {
+	fmt.Fprintf(w, "%-10s %-10s %14.4f %14.4f %14.4f %24s %10.4f %10.4g %12t\n",
+		name, m.Test, m.MeanA, m.MeanB, m.MeanDelta,
+		fmt.Sprintf("[%.4f, %.4f]", m.CILow, m.CIHigh), m.Statistic, m.PValue, m.Significant)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWilcoxonSignedRank(t *testing.T) {
+	// Hand-computed: a vs b have paired diffs [1, -1, 1, -1, 3, 0], the
+	// trailing zero-diff pair is dropped, leaving four tied |1| diffs
+	// (average rank 2.5 each) and one |3| diff (rank 5). Three of the
+	// non-zero diffs are positive (ranks 2.5+2.5+5=10), two are negative
+	// (ranks 2.5+2.5=5), so W = min(10, 5) = 5.
+	a := []float64{5, 3, 8, 5, 9, 7}
+	b := []float64{6, 2, 9, 4, 12, 7}
+
+	w, p := wilcoxonSignedRank(a, b)
+
+	require.InDelta(t, 5.0, w, 1e-9)
+	require.InDelta(t, 0.5001842570707944, p, 1e-9)
+}
+
+func TestWilcoxonSignedRankAllTiedDiffsAreDropped(t *testing.T) {
+	a := []float64{1, 2, 3}
+	b := []float64{1, 2, 3}
+
+	w, p := wilcoxonSignedRank(a, b)
+
+	require.Equal(t, 0.0, w)
+	require.Equal(t, 1.0, p)
+}
+
+func TestAssignRanksAveragesTies(t *testing.T) {
+	diffs := []rankable{
+		{diff: 1, abs: 1},
+		{diff: -1, abs: 1},
+		{diff: 2, abs: 2},
+	}
+
+	ranks := assignRanks(diffs)
+
+	require.Equal(t, []float64{1.5, 1.5, 3}, ranks)
+}
+
+func TestMcNemarTestAllConcordant(t *testing.T) {
+	// a and b agree on every query (both hit or both miss), so there are
+	// no discordant pairs and the test short-circuits to p=1.
+	a := []float64{1, 0, 1, 1, 0}
+	b := []float64{2, 0, 3, 1, 0}
+
+	statistic, p := mcNemarTest(a, b)
+
+	require.Equal(t, 0.0, statistic)
+	require.Equal(t, 1.0, p)
+}
+
+func TestMcNemarTestDiscordantPairs(t *testing.T) {
+	// a hits where b misses on 1 query (n10=1), b hits where a misses on
+	// 5 queries (n01=5); statistic = (|5-1|-1)^2 / 6 = 1.5.
+	a := []float64{1, 0, 0, 0, 0, 0}
+	b := []float64{0, 1, 1, 1, 1, 1}
+
+	statistic, p := mcNemarTest(a, b)
+
+	require.InDelta(t, 1.5, statistic, 1e-9)
+	require.InDelta(t, math.Erfc(math.Sqrt(0.75)), p, 1e-9)
+}
+
+func TestBootstrapMeanDeltaCI(t *testing.T) {
+	a := []float64{1, 2, 3, 4, 5}
+	b := []float64{2, 3, 5, 4, 9}
+	deltas := []float64{1, 1, 2, 0, 4}
+
+	minDelta, maxDelta := deltas[0], deltas[0]
+	var sum float64
+	for _, d := range deltas {
+		sum += d
+		if d < minDelta {
+			minDelta = d
+		}
+		if d > maxDelta {
+			maxDelta = d
+		}
+	}
+	meanDelta := sum / float64(len(deltas))
+
+	rand.Seed(42)
+	low, high := bootstrapMeanDeltaCI(a, b, bootstrapIterations)
+
+	require.LessOrEqual(t, low, high)
+	require.GreaterOrEqual(t, low, minDelta)
+	require.LessOrEqual(t, high, maxDelta)
+	require.InDelta(t, meanDelta, (low+high)/2, 1.5)
+}
+
+func TestBootstrapMeanDeltaCIEmptyInput(t *testing.T) {
+	low, high := bootstrapMeanDeltaCI(nil, nil, bootstrapIterations)
+
+	require.Equal(t, 0.0, low)
+	require.Equal(t, 0.0, high)
+}
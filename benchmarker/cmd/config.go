@@ -8,72 +8,159 @@ import (
 )
 
 type Config struct {
-	Mode                     string
-	Origin                   string
-	Queries                  int
-	QueriesFile              string
-	Parallel                 int
-	Limit                    int
-	ClassName                string
-	NamedVector              string
-	IndexType                string
-	ReplicationFactor        int
-	API                      string
-	HttpAuth                 string
-	Dimensions               int
-	MultiVectorDimensions    int
-	MuveraEnabled            bool
-	MuveraKSim               int
-	MuveraDProjections       int
-	MuveraRepetition         int
-	DB                       string
-	WhereFilter              string
-	OutputFormat             string
-	OutputFile               string
-	BenchmarkFile            string
-	BatchSize                int
-	Shards                   int
-	DistanceMetric           string
-	MaxConnections           int
-	Labels                   string
-	LabelMap                 map[string]string
-	EfConstruction           int
-	EfArray                  string
-	QueryOnly                bool
-	QueryDuration            int
-	BQ                       bool
-	Cache                    bool
-	RescoreLimit             int
-	PQ                       string
-	SQ                       string
-	LASQ                     string
-	SkipQuery                bool
-	SkipAsyncReady           bool
-	SkipTombstonesEmpty      bool
-	SkipMemoryStats          bool
-	PQRatio                  uint
-	PQSegments               uint
-	TrainingLimit            int
-	Tenant                   string
-	StartTenantNum           int
-	NumTenants               int
-	ExistingSchema           bool
-	HttpOrigin               string
-	HttpScheme               string
-	UpdatePercentage         float64
-	UpdateRandomized         bool
-	UpdateIterations         int
-	Offset                   int
-	CleanupIntervalSeconds   int
-	QueryDelaySeconds        int
-	DynamicThreshold         int
-	Filter                   bool
-	FlatSearchCutoff         int
-	FilterStrategy           string
-	AsyncReplicationEnabled  bool
-	MemoryMonitoringEnabled  bool
-	MemoryMonitoringInterval int
-	MemoryMonitoringFile     string
+	Mode                      string
+	Origin                    string
+	Queries                   int
+	QueriesFile               string
+	Parallel                  int
+	Limit                     int
+	ClassName                 string
+	NamedVector               string
+	IndexType                 string
+	ReplicationFactor         int
+	API                       string
+	HttpAuth                  string
+	Dimensions                int
+	MultiVectorDimensions     int
+	MuveraEnabled             bool
+	MuveraKSim                int
+	MuveraDProjections        int
+	MuveraRepetition          int
+	DB                        string
+	WhereFilter               string
+	OutputFormat              string
+	OutputFile                string
+	BenchmarkFile             string
+	BatchSize                 int
+	Shards                    int
+	DistanceMetric            string
+	MaxConnections            int
+	Labels                    string
+	LabelMap                  map[string]string
+	EfConstruction            int
+	EfArray                   string
+	QueryOnly                 bool
+	QueryDuration             int
+	QueryShuffle              bool
+	ImportWorkers             int
+	ImportQueueDepth          int
+	WarmupQueries             int
+	WarmupDuration            int
+	BQ                        bool
+	Cache                     bool
+	RescoreLimit              int
+	PQ                        string
+	SQ                        string
+	LASQ                      string
+	RaBitQ                    string
+	RaBitQBits                uint
+	RaBitQRotation            string
+	SkipQuery                 bool
+	SkipAsyncReady            bool
+	SkipTombstonesEmpty       bool
+	SkipMemoryStats           bool
+	PQRatio                   uint
+	PQSegments                uint
+	TrainingLimit             int
+	Tenant                    string
+	StartTenantNum            int
+	NumTenants                int
+	ExistingSchema            bool
+	HttpOrigin                string
+	HttpScheme                string
+	UpdatePercentage          float64
+	UpdateRandomized          bool
+	UpdateIterations          int
+	Offset                    int
+	CleanupIntervalSeconds    int
+	QueryDelaySeconds         int
+	DynamicThreshold          int
+	Filter                    bool
+	FilterPredicate           string
+	FlatSearchCutoff          int
+	FilterStrategy            string
+	AsyncReplicationEnabled   bool
+	MemoryMonitoringEnabled   bool
+	MemoryMonitoringInterval  int
+	MemoryMonitoringFile      string
+	PushGatewayURL            string
+	PushGatewayJob            string
+	PushGatewayInstance       string
+	QueryTimeoutSeconds       int
+	RunDeadlineSeconds        int
+	FilterColumn              string
+	FilterSelectivity         float64
+	MultiVectorSubsets        string
+	NumpyDir                  string
+	ReaderParallel            int
+	RecordPerQuery            bool
+	QueryDistribution         string
+	ReferenceDataset          string
+	PCAComponents             int
+	PCASampleSize             int
+	QueryNoiseStddev          float64
+	NormalizeQueries          bool
+	QueryModel                *queryDistributionModel
+	QueryPattern              string
+	RateQPS                   float64
+	RateDistribution          string
+	PrometheusConfig          PrometheusConfig
+	MaxRetries                int
+	RetryableCodes            string
+	GraphiteConfig            GraphiteConfig
+	CollectServerMetrics      bool
+	PrometheusQueryURL        string
+	ServerMetricsIntervalSec  int
+	ReplayTraceFile           string
+	ReplayMode                string
+	SpeedFactor               float64
+	InfluxDBConfig            InfluxDBConfig
+	RemoteWriteConfig         RemoteWriteConfig
+	Sinks                     []string
+	StatsDAddress             string
+	StatsDPrefix              string
+	SinkFile                  string
+	Silent                    bool
+	NoProgress                bool
+	CheckpointFile            string
+	Resume                    bool
+	DatasetURL                string
+	DatasetCacheDir           string
+	DatasetPrefetch           int
+	CPUProfile                string
+	MemProfile                string
+	MemProfileRate            int
+	Trace                     string
+	Baseline                  string
+	FailOnRegression          string
+	ParallelArray             string
+	LimitArray                string
+	BatchSizeArray            string
+	MaxConnectionsArray       string
+	EfConstructionArray       string
+	RescoreLimitArray         string
+	SweepRebuild              bool
+	CurrentEf                 int
+	GrpcCompression           string
+	GrpcMaxRecvMsgSize        int
+	GrpcInitialWindowSize     int
+	GrpcInitialConnWindowSize int
+	GrpcWriteBufferSize       int
+	QueryOrder                string
+	QuerySeed                 int64
+	QueryRepeat               int
+	GrpcCAFile                string
+	GrpcClientCertFile        string
+	GrpcClientKeyFile         string
+	GrpcTLSSkipVerify         bool
+	GrpcAuthToken             string
+	GrpcKeepaliveTimeSeconds  int
+	GrpcKeepaliveTimeout      int
+	GrpcKeepaliveNoStream     bool
+	GrpcRetryMaxAttempts      int
+	GrpcRetryPerTryTimeoutSec int
+	GrpcRetryBackoffBaseMs    int
+	GrpcRetryBackoffMaxMs     int
 }
 
 func (c *Config) Validate() error {
@@ -91,6 +178,8 @@ func (c *Config) Validate() error {
 		return c.validateDataset()
 	case "ann-benchmark":
 		return c.validateANN()
+	case "replay":
+		return c.validateReplay()
 	default:
 		return errors.Errorf("unrecognized mode %q", c.Mode)
 	}
@@ -163,12 +252,30 @@ func (c *Config) parseLabels() {
 	c.LabelMap = result
 }
 
+func (c Config) validateReplay() error {
+	if c.ReplayTraceFile == "" {
+		return errors.Errorf("a --trace-file must be provided")
+	}
+
+	switch c.ReplayMode {
+	case "as_fast_as_possible", "real_time", "scaled":
+	default:
+		return errors.Errorf("unsupported replay mode %q, must be one of [as_fast_as_possible, real_time, scaled]", c.ReplayMode)
+	}
+
+	if c.API != "grpc" {
+		return errors.Errorf("only grpc is supported for replay")
+	}
+
+	return nil
+}
+
 func (c Config) validateANN() error {
 	if c.BenchmarkFile == "" {
 		return errors.Errorf("a vector benchmark file must be provided")
 	}
 
-	if c.API != "grpc" {
+	if c.API != "grpc" && c.IndexType != "opensearch-knn" {
 		return errors.Errorf("only grpc is supported for ann-benchmark")
 	}
 
@@ -11,7 +11,7 @@ import (
 var datasetCmd = &cobra.Command{
 	Use:   "dataset",
 	Short: "Benchmark vectors from an existing dataset",
-	Long:  `Specify an existing dataset as a list of query vectors in a .json file to parse the query vectors and then query them with the specified parallelism`,
+	Long:  `Specify an existing dataset as a list of query vectors in a .json file to parse the query vectors and then query them with the specified parallelism. With --queryDuration, the query vectors are cycled (optionally reshuffled on each lap via --queryShuffle) for the given duration instead of being run once.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		cfg := globalConfig
 		cfg.Mode = "dataset"
@@ -49,6 +49,10 @@ var datasetCmd = &cobra.Command{
 		if cfg.OutputFile != "" {
 			infof("results succesfully written to %q", cfg.OutputFile)
 		}
+
+		if result.Interrupted {
+			os.Exit(1)
+		}
 	},
 }
 
@@ -66,12 +70,40 @@ func initDataset() {
 		"where", "w", "", "An entire where filter as a string")
 	datasetCmd.PersistentFlags().StringVarP(&globalConfig.API,
 		"api", "a", "graphql", "The API to use on benchmarks")
+	datasetCmd.PersistentFlags().IntVar(&globalConfig.QueryDuration,
+		"queryDuration", 0, "Instead of running the queries file once, cycle through it for the specified duration in seconds, reporting the median across iterations (default 0, disabled)")
+	datasetCmd.PersistentFlags().BoolVar(&globalConfig.QueryShuffle,
+		"queryShuffle", true, "Reshuffle the queries file each time --queryDuration cycles past the end of it, so a long soak run doesn't replay the exact same sequence every lap")
 	datasetCmd.PersistentFlags().StringVarP(&globalConfig.Origin,
 		"origin", "u", "http://localhost:8080", "The origin that Weaviate is running at")
 	datasetCmd.PersistentFlags().StringVarP(&globalConfig.OutputFormat,
 		"format", "f", "text", "Output format, one of [text, json]")
 	datasetCmd.PersistentFlags().StringVarP(&globalConfig.OutputFile,
 		"output", "o", "", "Filename for an output file. If none provided, output to stdout only")
+	datasetCmd.PersistentFlags().StringVar(&globalConfig.GrpcCAFile,
+		"grpcCAFile", "", "PEM-encoded CA bundle to verify the gRPC server certificate against when --httpScheme=https (default: the system CA pool)")
+	datasetCmd.PersistentFlags().StringVar(&globalConfig.GrpcClientCertFile,
+		"grpcClientCertFile", "", "PEM-encoded client certificate for mTLS, requires --grpcClientKeyFile (default disabled)")
+	datasetCmd.PersistentFlags().StringVar(&globalConfig.GrpcClientKeyFile,
+		"grpcClientKeyFile", "", "PEM-encoded client private key for mTLS, requires --grpcClientCertFile (default disabled)")
+	datasetCmd.PersistentFlags().BoolVar(&globalConfig.GrpcTLSSkipVerify,
+		"grpcTLSSkipVerify", false, "Skip verifying the gRPC server certificate when --httpScheme=https; mutually exclusive with --grpcCAFile, which it would otherwise silently ignore")
+	datasetCmd.PersistentFlags().StringVar(&globalConfig.GrpcAuthToken,
+		"grpcAuthToken", "", "Bearer token sent as per-RPC credentials on the gRPC query connection, for clusters that enforce auth on their gRPC port (default disabled)")
+	datasetCmd.PersistentFlags().IntVar(&globalConfig.GrpcKeepaliveTimeSeconds,
+		"grpcKeepaliveTime", 0, "Send a gRPC keepalive ping after this many seconds of inactivity, so long benchmarks don't lose an idle connection to a proxy or load balancer (default disabled)")
+	datasetCmd.PersistentFlags().IntVar(&globalConfig.GrpcKeepaliveTimeout,
+		"grpcKeepaliveTimeout", 20, "Seconds to wait for a keepalive ping ack before considering the connection dead (only applies when --grpcKeepaliveTime is set)")
+	datasetCmd.PersistentFlags().BoolVar(&globalConfig.GrpcKeepaliveNoStream,
+		"grpcKeepalivePermitWithoutStream", false, "Send keepalive pings even when there are no in-flight RPCs (only applies when --grpcKeepaliveTime is set)")
+	datasetCmd.PersistentFlags().IntVar(&globalConfig.GrpcRetryMaxAttempts,
+		"grpcRetryMaxAttempts", 0, "Max gRPC-level retry attempts for transient errors (default disabled; this command has no --max-retries flag, so query RPCs are not otherwise retried)")
+	datasetCmd.PersistentFlags().IntVar(&globalConfig.GrpcRetryPerTryTimeoutSec,
+		"grpcRetryPerTryTimeout", 0, "Per-attempt timeout in seconds for --grpcRetryMaxAttempts (default: no per-attempt timeout beyond the RPC's own context deadline)")
+	datasetCmd.PersistentFlags().IntVar(&globalConfig.GrpcRetryBackoffBaseMs,
+		"grpcRetryBackoffBaseMs", 100, "Base exponential backoff in milliseconds between gRPC-level retry attempts")
+	datasetCmd.PersistentFlags().IntVar(&globalConfig.GrpcRetryBackoffMaxMs,
+		"grpcRetryBackoffMaxMs", 0, "Cap on the exponential backoff between gRPC-level retry attempts (default disabled: backoff grows unbounded with attempt count)")
 }
 
 type Queries [][]float32
@@ -95,9 +127,9 @@ func parseVectorsFromFile(cfg Config) (Queries, error) {
 func benchmarkDataset(cfg Config, queries Queries) Results {
 	cfg.Queries = len(queries)
 
-	i := 0
-	return benchmark(cfg, func(className string) QueryWithNeighbors {
-		defer func() { i++ }()
+	next := cyclingIndexFn(len(queries), cfg.QueryShuffle)
+	getQuery := func(className string) QueryWithNeighbors {
+		i := next()
 
 		if cfg.API == "graphql" {
 			return QueryWithNeighbors{
@@ -125,5 +157,13 @@ func benchmarkDataset(cfg Config, queries Queries) Results {
 		}
 
 		return QueryWithNeighbors{}
-	})
+	}
+
+	if cfg.QueryDuration > 0 {
+		return benchmarkDuration(cfg, func(iteration int) Results {
+			return benchmark(cfg, getQuery)
+		})
+	}
+
+	return benchmark(cfg, getQuery)
 }
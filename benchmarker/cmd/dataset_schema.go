@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/weaviate/hdf5"
+)
+
+// DatasetSchema describes how to interpret an HDF5 benchmark file's "train"
+// dataset, read from its weaviate_benchmark_format attribute the same way a
+// container format gets versioned with a header byte. Absence of the
+// attribute means DatasetFormatV1, the original implicit layout: dense
+// float32/float64 train/test/neighbors datasets, optional
+// train_categories/test_categories int filters, and ColBERT multi-vectors
+// selected out-of-band via --multi-vector-dim. DatasetFormatV2 declares its
+// layout explicitly via sibling attributes instead, so TestVectors/
+// TrainFilters/StreamTrainData can dispatch on VectorEncoding/
+// FilterColumns/NeighborLayout and adding a new corpus variant is one new
+// reader function rather than a new flag threaded through every command.
+type DatasetSchema struct {
+	Format         int
+	VectorEncoding string
+	FilterColumns  []string
+	NeighborLayout string
+}
+
+const (
+	// DatasetFormatV1 is the implicit legacy layout, assumed whenever the
+	// weaviate_benchmark_format attribute is absent.
+	DatasetFormatV1 = 1
+	// DatasetFormatV2 declares its layout explicitly via the
+	// vector_encoding/filter_columns/neighbor_layout attributes below.
+	DatasetFormatV2 = 2
+)
+
+const (
+	VectorEncodingDenseF32  = "dense_f32"
+	VectorEncodingDenseF16  = "dense_f16"
+	VectorEncodingInt8      = "int8"
+	VectorEncodingColbert   = "colbert"
+	VectorEncodingSparseCSR = "sparse_csr"
+)
+
+const (
+	NeighborLayoutFixedK = "fixed_k"
+	NeighborLayoutRagged = "ragged"
+)
+
+// readDatasetSchema inspects the "train" dataset's weaviate_benchmark_format
+// attribute and returns the resulting DatasetSchema, defaulting to the v1
+// implicit layout (with vector encoding inferred from multiVectorDimension,
+// same as before this existed) when the attribute isn't present. The hdf5
+// bindings only expose attribute access on Group/Dataset, not File, so the
+// format descriptor lives on "train" rather than the file root.
+func readDatasetSchema(file *hdf5.File, multiVectorDimension int) DatasetSchema {
+	schema := DatasetSchema{
+		Format:         DatasetFormatV1,
+		VectorEncoding: VectorEncodingDenseF32,
+		NeighborLayout: NeighborLayoutFixedK,
+	}
+	if multiVectorDimension > 0 {
+		schema.VectorEncoding = VectorEncodingColbert
+	}
+
+	dataset, err := file.OpenDataset("train")
+	if err != nil {
+		log.Fatalf("Error opening dataset: %v", err)
+	}
+	defer dataset.Close()
+
+	formatAttr, err := dataset.OpenAttribute("weaviate_benchmark_format")
+	if err != nil {
+		return schema
+	}
+	defer formatAttr.Close()
+
+	var format uint8
+	if err := formatAttr.Read(&format, hdf5.T_NATIVE_UINT8); err != nil {
+		log.WithError(err).Warn("failed to read weaviate_benchmark_format attribute, assuming format v1")
+		return schema
+	}
+
+	if format != DatasetFormatV2 {
+		schema.Format = int(format)
+		return schema
+	}
+
+	schema.Format = DatasetFormatV2
+	schema.VectorEncoding = readStringAttribute(dataset, "vector_encoding", schema.VectorEncoding)
+	schema.NeighborLayout = readStringAttribute(dataset, "neighbor_layout", schema.NeighborLayout)
+
+	if columns := readStringAttribute(dataset, "filter_columns", ""); columns != "" {
+		schema.FilterColumns = strings.Split(columns, ",")
+	}
+
+	return schema
+}
+
+func readStringAttribute(dataset *hdf5.Dataset, name string, fallback string) string {
+	attr, err := dataset.OpenAttribute(name)
+	if err != nil {
+		return fallback
+	}
+	defer attr.Close()
+
+	var value string
+	if err := attr.Read(&value, nil); err != nil {
+		log.WithError(err).Warnf("failed to read %q attribute", name)
+		return fallback
+	}
+	return value
+}
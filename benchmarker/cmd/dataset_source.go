@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DatasetSource abstracts reading byte ranges out of a dataset that may live
+// on local disk or in an object store, so a fixed-record-size loader like
+// loadFvecsStreaming can stream batches via range reads instead of requiring
+// the whole file to fit on local disk. See openDatasetSource for the
+// --dataset-url schemes this supports.
+//
+// The hdf5 C library bindings this package uses (github.com/weaviate/hdf5)
+// only open local files - there is no hook for per-hyperslab range GETs
+// without forking that dependency, so a full h5coro-style byte-range
+// planner for HDF5 is out of scope here. Instead, a remote --dataset-url
+// pointing at an .hdf5/.h5 file is downloaded once to --dataset-cache-dir
+// via downloadDatasetToCache (itself using this abstraction, with bounded
+// memory via --dataset-prefetch) and then opened through the existing local
+// hdf5.OpenFile path unchanged, so HDF5 throughput is unaffected once
+// cached. fvecs/bvecs datasets need no such cache: their fixed record size
+// makes genuine range-GET streaming straightforward, so loadFvecsStreaming
+// reads a remote --dataset-url directly through this interface.
+type DatasetSource interface {
+	// ReadRange returns the length bytes starting at offset.
+	ReadRange(offset, length int64) ([]byte, error)
+	// Size returns the total size of the underlying object.
+	Size() (int64, error)
+	Close() error
+}
+
+// isRemoteDatasetURL reports whether path names an object-store/http(s) URL
+// rather than a plain local filesystem path.
+func isRemoteDatasetURL(path string) bool {
+	u, err := url.Parse(path)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "http", "https", "s3", "gs":
+		return true
+	default:
+		return false
+	}
+}
+
+// openDatasetSource dispatches rawURL to a DatasetSource by scheme:
+// file:// and bare paths via the local filesystem, http(s):// via range
+// GETs, and s3:// / gs:// by translating to their public virtual-hosted
+// HTTPS endpoint and delegating to the http(s) source. s3/gs support is
+// therefore limited to public or pre-signed URLs - there is no SigV4/OAuth
+// signing here, so a private bucket needs a pre-signed https:// URL instead.
+func openDatasetSource(rawURL string) (DatasetSource, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing dataset url %q: %w", rawURL, err)
+	}
+
+	switch u.Scheme {
+	case "", "file":
+		path := rawURL
+		if u.Scheme == "file" {
+			path = u.Path
+		}
+		return newFileDatasetSource(path)
+	case "http", "https":
+		return newHTTPDatasetSource(rawURL)
+	case "s3":
+		return newHTTPDatasetSource(fmt.Sprintf("https://%s.s3.amazonaws.com%s", u.Host, u.Path))
+	case "gs":
+		return newHTTPDatasetSource(fmt.Sprintf("https://storage.googleapis.com/%s%s", u.Host, u.Path))
+	default:
+		return nil, fmt.Errorf("unsupported dataset url scheme %q", u.Scheme)
+	}
+}
+
+// fileDatasetSource is the local-disk DatasetSource, used both for plain
+// paths and as the fallback every other source reduces to once cached.
+type fileDatasetSource struct {
+	f *os.File
+}
+
+func newFileDatasetSource(path string) (*fileDatasetSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &fileDatasetSource{f: f}, nil
+}
+
+func (s *fileDatasetSource) ReadRange(offset, length int64) ([]byte, error) {
+	buf := make([]byte, length)
+	if _, err := s.f.ReadAt(buf, offset); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (s *fileDatasetSource) Size() (int64, error) {
+	info, err := s.f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func (s *fileDatasetSource) Close() error {
+	return s.f.Close()
+}
+
+// httpDatasetSource reads byte ranges via HTTP Range requests, the
+// primitive both --dataset-url=http(s):// and the s3://, gs:// translations
+// in openDatasetSource are built on.
+type httpDatasetSource struct {
+	url    string
+	client *http.Client
+}
+
+func newHTTPDatasetSource(rawURL string) (*httpDatasetSource, error) {
+	return &httpDatasetSource{url: rawURL, client: &http.Client{}}, nil
+}
+
+func (s *httpDatasetSource) ReadRange(offset, length int64) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("range request to %s returned status %d, server may not support range requests", s.url, resp.StatusCode)
+	}
+
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(resp.Body, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (s *httpDatasetSource) Size() (int64, error) {
+	resp, err := s.client.Head(s.url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("HEAD %s returned status %d", s.url, resp.StatusCode)
+	}
+
+	size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing Content-Length from HEAD %s: %w", s.url, err)
+	}
+	return size, nil
+}
+
+func (s *httpDatasetSource) Close() error {
+	return nil
+}
+
+// datasetSourceReaderAt adapts a DatasetSource to io.ReaderAt so it can be
+// fed through io.NewSectionReader wherever a local *os.File would otherwise
+// be used, letting readFvecsRecord stay agnostic to where the bytes
+// actually came from.
+type datasetSourceReaderAt struct {
+	src DatasetSource
+}
+
+func (r *datasetSourceReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	buf, err := r.src.ReadRange(off, int64(len(p)))
+	if err != nil {
+		return 0, err
+	}
+	n := copy(p, buf)
+	if n < len(p) {
+		return n, io.ErrUnexpectedEOF
+	}
+	return n, nil
+}
+
+// downloadDatasetToCache streams src to cacheDir/name using prefetch
+// concurrent range-GET workers, keeping at most prefetch chunks in memory
+// at once, and returns the local cached path.
+func downloadDatasetToCache(src DatasetSource, cacheDir string, name string, prefetch int) (string, error) {
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return "", fmt.Errorf("error creating dataset cache dir %s: %w", cacheDir, err)
+	}
+
+	size, err := src.Size()
+	if err != nil {
+		return "", err
+	}
+
+	cachePath := cacheDir + string(os.PathSeparator) + name
+	out, err := os.Create(cachePath)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	const downloadChunkSize = 32 * 1024 * 1024
+
+	if prefetch < 1 {
+		prefetch = 1
+	}
+
+	type downloadJob struct{ offset, length int64 }
+	jobs := make(chan downloadJob, prefetch*2)
+
+	var wg sync.WaitGroup
+	var writeMu sync.Mutex
+	var errMu sync.Mutex
+	var firstErr error
+
+	recordErr := func(err error) {
+		errMu.Lock()
+		defer errMu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for w := 0; w < prefetch; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				buf, err := src.ReadRange(j.offset, j.length)
+				if err != nil {
+					recordErr(err)
+					continue
+				}
+
+				writeMu.Lock()
+				_, err = out.WriteAt(buf, j.offset)
+				writeMu.Unlock()
+				if err != nil {
+					recordErr(err)
+				}
+			}
+		}()
+	}
+
+	for offset := int64(0); offset < size; offset += downloadChunkSize {
+		length := int64(downloadChunkSize)
+		if offset+length > size {
+			length = size - offset
+		}
+		jobs <- downloadJob{offset: offset, length: length}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return "", firstErr
+	}
+
+	return cachePath, nil
+}
+
+// resolveDatasetFile returns the local path loaders should open for
+// cfg.BenchmarkFile / cfg.DatasetURL. With no --dataset-url it's a no-op.
+// fvecs/bvecs URLs are returned unchanged since loadFvecsStreaming reads
+// them directly via DatasetSource; anything else (i.e. HDF5, which can only
+// be opened locally) is downloaded once to --dataset-cache-dir and the
+// cached path is returned instead.
+func resolveDatasetFile(cfg *Config) (string, error) {
+	if cfg.DatasetURL == "" {
+		return cfg.BenchmarkFile, nil
+	}
+
+	switch strings.ToLower(filepath.Ext(cfg.DatasetURL)) {
+	case ".fvecs", ".bvecs":
+		return cfg.DatasetURL, nil
+	}
+
+	src, err := openDatasetSource(cfg.DatasetURL)
+	if err != nil {
+		return "", fmt.Errorf("error opening dataset url %q: %w", cfg.DatasetURL, err)
+	}
+	defer src.Close()
+
+	cacheDir := cfg.DatasetCacheDir
+	if cacheDir == "" {
+		cacheDir = os.TempDir()
+	}
+
+	log.WithFields(log.Fields{"url": cfg.DatasetURL, "cacheDir": cacheDir, "prefetch": cfg.DatasetPrefetch}).
+		Info("Downloading remote dataset to local cache")
+
+	cached, err := downloadDatasetToCache(src, cacheDir, filepath.Base(cfg.DatasetURL), cfg.DatasetPrefetch)
+	if err != nil {
+		return "", fmt.Errorf("error downloading dataset url %q: %w", cfg.DatasetURL, err)
+	}
+
+	return cached, nil
+}
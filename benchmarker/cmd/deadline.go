@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"net"
+	"time"
+)
+
+// deadlineConn wraps a net.Conn and re-applies a fixed read/write deadline
+// before every operation, so a connection that goes quiet mid-query is
+// torn down instead of hanging past --query-timeout. Go's net.Conn already
+// exposes SetDeadline/SetReadDeadline/SetWriteDeadline; this just makes
+// sure they're set on every call rather than relying on callers to
+// remember to do it themselves.
+type deadlineConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func newDeadlineConn(conn net.Conn, timeout time.Duration) net.Conn {
+	if timeout <= 0 {
+		return conn
+	}
+	return &deadlineConn{Conn: conn, timeout: timeout}
+}
+
+func (c *deadlineConn) Read(b []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(b)
+}
+
+func (c *deadlineConn) Write(b []byte) (int, error) {
+	if err := c.Conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Write(b)
+}
@@ -0,0 +1,485 @@
+package cmd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// fvecsElemKind identifies the per-element encoding used by the Texmex
+// .fvecs/.bvecs/.ivecs formats: a little-endian int32 dim header followed by
+// dim elements of the given kind, records concatenated with no file-level
+// header. See http://corpus-texmex.irisa.fr/ for the format this mirrors.
+type fvecsElemKind int
+
+const (
+	fvecsFloat32 fvecsElemKind = iota
+	fvecsUint8
+	fvecsInt32
+)
+
+func fvecsElemSize(kind fvecsElemKind) int64 {
+	switch kind {
+	case fvecsUint8:
+		return 1
+	default:
+		return 4
+	}
+}
+
+// fvecsElemKindForExt dispatches on file extension: .fvecs is float32,
+// .bvecs is uint8, .ivecs is int32 (used for groundtruth neighbour ids).
+func fvecsElemKindForExt(path string) fvecsElemKind {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".bvecs":
+		return fvecsUint8
+	case ".ivecs":
+		return fvecsInt32
+	default:
+		return fvecsFloat32
+	}
+}
+
+// fvecsSource wraps an io.ReaderAt (a local *os.File, or a DatasetSource
+// range-GET reader for a remote --dataset-url) so the record-decoding logic
+// below is identical whether path named a local file or an object-store
+// URL. See dataset_source.go for the supported URL schemes.
+type fvecsSource struct {
+	ra      io.ReaderAt
+	size    int64
+	closeFn func() error
+}
+
+func openFvecsSource(path string) (*fvecsSource, error) {
+	if isRemoteDatasetURL(path) {
+		src, err := openDatasetSource(path)
+		if err != nil {
+			return nil, err
+		}
+		size, err := src.Size()
+		if err != nil {
+			src.Close()
+			return nil, err
+		}
+		return &fvecsSource{ra: &datasetSourceReaderAt{src: src}, size: size, closeFn: src.Close}, nil
+	}
+
+	f, err := newFileDatasetSource(path)
+	if err != nil {
+		return nil, err
+	}
+	size, err := f.Size()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &fvecsSource{ra: f.f, size: size, closeFn: f.Close}, nil
+}
+
+// recordReader returns an io.Reader over the length bytes starting at
+// offset, for feeding into readFvecsRecord.
+func (s *fvecsSource) recordReader(offset, length int64) io.Reader {
+	return io.NewSectionReader(s.ra, offset, length)
+}
+
+func (s *fvecsSource) Close() error {
+	return s.closeFn()
+}
+
+// readFvecsDim reads the leading 4-byte little-endian dimension header off
+// the first record of path. Every record in a Texmex file repeats this same
+// header, so reading it once is enough to compute record size for the rest.
+func readFvecsDim(path string) (int, error) {
+	src, err := openFvecsSource(path)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	var dim int32
+	if err := binary.Read(src.recordReader(0, 4), binary.LittleEndian, &dim); err != nil {
+		return 0, fmt.Errorf("error reading dim header of %s: %w", path, err)
+	}
+
+	return int(dim), nil
+}
+
+// fvecsRecordSize is the byte length of one dim-header-plus-vector record.
+func fvecsRecordSize(dim int, kind fvecsElemKind) int64 {
+	return 4 + int64(dim)*fvecsElemSize(kind)
+}
+
+// fvecsRowCount computes the number of records in path from its size,
+// without scanning: nRows = size / recordSize. Warns if the size isn't an
+// exact multiple of recordSize, which usually means a truncated download.
+func fvecsRowCount(path string, dim int, kind fvecsElemKind) (int, error) {
+	src, err := openFvecsSource(path)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	recordSize := fvecsRecordSize(dim, kind)
+	rows := src.size / recordSize
+	if src.size%recordSize != 0 {
+		log.Warnf("%s size %d is not an exact multiple of record size %d, file may be truncated",
+			path, src.size, recordSize)
+	}
+
+	return int(rows), nil
+}
+
+// readFvecsRecord reads the row-th record (dim header skipped) from r and
+// upcasts it to float32, for the vector-producing loaders. r must already be
+// positioned at the start of the row-th record.
+func readFvecsRecord(r io.Reader, dim int, kind fvecsElemKind) ([]float32, error) {
+	var header int32
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+
+	vec := make([]float32, dim)
+	switch kind {
+	case fvecsUint8:
+		buf := make([]byte, dim)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		for i, b := range buf {
+			vec[i] = float32(b)
+		}
+	case fvecsInt32:
+		buf := make([]int32, dim)
+		if err := binary.Read(r, binary.LittleEndian, &buf); err != nil {
+			return nil, err
+		}
+		for i, v := range buf {
+			vec[i] = float32(v)
+		}
+	default:
+		if err := binary.Read(r, binary.LittleEndian, &vec); err != nil {
+			return nil, err
+		}
+	}
+
+	return vec, nil
+}
+
+// loadFvecsFloat32 reads an entire .fvecs/.bvecs file at once, for test/query
+// vectors that are small enough to fit in memory in one go.
+func loadFvecsFloat32(path string) [][]float32 {
+	kind := fvecsElemKindForExt(path)
+	dim, err := readFvecsDim(path)
+	if err != nil {
+		log.Fatalf("Error reading %s: %v", path, err)
+	}
+
+	src, err := openFvecsSource(path)
+	if err != nil {
+		log.Fatalf("Error opening %s: %v", path, err)
+	}
+	defer src.Close()
+
+	recordSize := fvecsRecordSize(dim, kind)
+	rowCount := int(src.size / recordSize)
+
+	vectors := make([][]float32, rowCount)
+	for i := 0; i < rowCount; i++ {
+		vec, err := readFvecsRecord(src.recordReader(int64(i)*recordSize, recordSize), dim, kind)
+		if err != nil {
+			log.Fatalf("Error reading record %d of %s: %v", i, path, err)
+		}
+		vectors[i] = vec
+	}
+
+	return vectors
+}
+
+// loadIvecsNeighbors reads an entire .ivecs groundtruth file at once. It
+// reads elements as int32 directly rather than going through
+// readFvecsRecord's float32 upcast, since neighbour ids can exceed the
+// 24-bit mantissa float32 can represent exactly.
+func loadIvecsNeighbors(path string) [][]int {
+	dim, err := readFvecsDim(path)
+	if err != nil {
+		log.Fatalf("Error reading %s: %v", path, err)
+	}
+
+	src, err := openFvecsSource(path)
+	if err != nil {
+		log.Fatalf("Error opening %s: %v", path, err)
+	}
+	defer src.Close()
+
+	recordSize := fvecsRecordSize(dim, fvecsInt32)
+	rowCount := int(src.size / recordSize)
+
+	neighbors := make([][]int, rowCount)
+	for i := 0; i < rowCount; i++ {
+		reader := src.recordReader(int64(i)*recordSize, recordSize)
+
+		var header int32
+		if err := binary.Read(reader, binary.LittleEndian, &header); err != nil {
+			log.Fatalf("Error reading record %d of %s: %v", i, path, err)
+		}
+
+		buf := make([]int32, dim)
+		if err := binary.Read(reader, binary.LittleEndian, &buf); err != nil {
+			log.Fatalf("Error reading record %d of %s: %v", i, path, err)
+		}
+
+		row := make([]int, dim)
+		for j, v := range buf {
+			row[j] = int(v)
+		}
+		neighbors[i] = row
+	}
+
+	return neighbors
+}
+
+// fvecsStreamingJob/fvecsStreamingResult mirror hdf5StreamingJob/Result
+// (hdf5_dataset.go): one batch's row range plus its emission-order index,
+// handed to the reader worker pool.
+type fvecsStreamingJob struct {
+	index     int
+	rowOffset uint
+	batchRows uint
+}
+
+type fvecsStreamingResult struct {
+	index int
+	batch Batch
+}
+
+// loadFvecsStreaming reads path in cfg.BatchSize-sized chunks and emits them
+// on chunks, the .fvecs/.bvecs counterpart to loadHdf5Streaming. Since
+// record size is fixed and known up front, it reads straight from
+// startOffset's byte offset instead of scanning preceding records. Batches
+// are read by cfg.DatasetPrefetch workers over the shared io.ReaderAt (safe
+// for concurrent use, unlike Seek+Read), which is what lets a remote
+// --dataset-url keep several range GETs in flight instead of reading
+// strictly serially.
+func loadFvecsStreaming(path string, chunks chan<- Batch, cfg *Config, startOffset uint, maxRecords uint, filters []int) {
+	kind := fvecsElemKindForExt(path)
+	dim, err := readFvecsDim(path)
+	if err != nil {
+		log.Fatalf("Error reading %s: %v", path, err)
+	}
+
+	src, err := openFvecsSource(path)
+	if err != nil {
+		log.Fatalf("Error opening %s: %v", path, err)
+	}
+	defer src.Close()
+
+	recordSize := fvecsRecordSize(dim, kind)
+	rows := uint(src.size / recordSize)
+
+	i := uint(0)
+	if maxRecords != 0 && maxRecords < rows {
+		rows = maxRecords
+	}
+	if startOffset != 0 && i < rows {
+		i = startOffset
+	}
+
+	batchSize := uint(cfg.BatchSize)
+	prefetch := cfg.DatasetPrefetch
+	if prefetch < 1 {
+		prefetch = 1
+	}
+
+	log.WithFields(log.Fields{"rows": rows, "dimensions": dim, "prefetch": prefetch}).Printf("Reading fvecs/bvecs dataset")
+
+	jobs := make(chan fvecsStreamingJob, prefetch*2)
+	results := make(chan fvecsStreamingResult, prefetch*2)
+
+	var workers sync.WaitGroup
+	for w := 0; w < prefetch; w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range jobs {
+				chunkData := make([][]float32, job.batchRows)
+				for r := uint(0); r < job.batchRows; r++ {
+					row := job.rowOffset + r
+					vec, err := readFvecsRecord(src.recordReader(int64(row)*recordSize, recordSize), dim, kind)
+					if err != nil {
+						log.Fatalf("Error reading row %d of %s: %v", row, path, err)
+					}
+					chunkData[r] = vec
+				}
+
+				filter := []int{}
+				if len(filters) > 0 {
+					filter = filters[job.rowOffset : job.rowOffset+job.batchRows]
+				}
+
+				results <- fvecsStreamingResult{
+					index: job.index,
+					batch: Batch{Vectors: chunkData, Offset: int(job.rowOffset), Filters: filter},
+				}
+			}
+		}()
+	}
+
+	go func() {
+		index := 0
+		for r := i; r < rows; r += batchSize {
+			batchRows := batchSize
+			if r+batchSize > rows {
+				batchRows = rows - r
+			}
+			jobs <- fvecsStreamingJob{index: index, rowOffset: r, batchRows: batchRows}
+			index++
+		}
+		close(jobs)
+	}()
+
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	// Reorder results by index so batches still arrive in ascending Offset
+	// order even though workers can finish them out of order, same pattern
+	// as loadHdf5StreamingParallel.
+	pending := make(map[int]Batch)
+	next := 0
+	for r := range results {
+		pending[r.index] = r.batch
+		for {
+			batch, ok := pending[next]
+			if !ok {
+				break
+			}
+
+			if (uint(batch.Offset)+batchSize)%10000 == 0 {
+				log.Printf("Imported %d/%d rows", uint(batch.Offset)+batchSize, rows)
+			}
+
+			chunks <- batch
+			delete(pending, next)
+			next++
+		}
+	}
+}
+
+// FvecsDataset implements Dataset over a Texmex fvecs/bvecs/ivecs triple. A
+// train file configured as e.g. sift_base.fvecs is expected to have sibling
+// sift_query.fvecs and sift_groundtruth.ivecs files, following the naming
+// convention used by the public SIFT1M/DEEP1B/BIGANN downloads - swap
+// "_base" for "_query"/"_groundtruth" to derive them.
+type FvecsDataset struct {
+	trainFile  string
+	queryFile  string
+	groundFile string
+
+	dimension int
+	trainRows int
+	prefetch  int
+}
+
+// NewFvecsDataset opens filePath (the train/base file) and derives its
+// sibling query/groundtruth file paths. filters are not supported by the
+// Texmex format, so useFilters=true is rejected with a fatal error; the
+// parameter only keeps the same shape as NewHdf5Dataset. readerParallel is reused as
+// loadFvecsStreaming's range-read prefetch depth, the fvecs counterpart to
+// what it means for NewHdf5Dataset.
+func NewFvecsDataset(filePath string, multiVectorDimension int, useFilters bool, readerParallel int) *FvecsDataset {
+	if useFilters {
+		log.Fatalf("fvecs/bvecs datasets do not support --filter")
+	}
+
+	dim, err := readFvecsDim(filePath)
+	if err != nil {
+		log.Fatalf("Error reading %s: %v", filePath, err)
+	}
+	if multiVectorDimension > 0 {
+		dim = multiVectorDimension
+	}
+
+	rows, err := fvecsRowCount(filePath, dim, fvecsElemKindForExt(filePath))
+	if err != nil {
+		log.Fatalf("Error statting %s: %v", filePath, err)
+	}
+
+	if readerParallel < 1 {
+		readerParallel = 1
+	}
+
+	return &FvecsDataset{
+		trainFile:  filePath,
+		queryFile:  fvecsSiblingPath(filePath, "_base", "_query"),
+		groundFile: fvecsGroundtruthPath(filePath),
+		dimension:  dim,
+		trainRows:  rows,
+		prefetch:   readerParallel,
+	}
+}
+
+// fvecsSiblingPath swaps the from suffix in path's base name for to,
+// preserving path's extension and directory.
+func fvecsSiblingPath(path, from, to string) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(filepath.Base(path), ext)
+	return filepath.Join(filepath.Dir(path), strings.Replace(base, from, to, 1)+ext)
+}
+
+// fvecsGroundtruthPath derives the .ivecs groundtruth path sitting alongside
+// path, e.g. sift_base.fvecs -> sift_groundtruth.ivecs.
+func fvecsGroundtruthPath(path string) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(filepath.Base(path), ext)
+	return filepath.Join(filepath.Dir(path), strings.Replace(base, "_base", "_groundtruth", 1)+".ivecs")
+}
+
+func (ds *FvecsDataset) Close() {}
+
+func (ds *FvecsDataset) Dimension() int {
+	return ds.dimension
+}
+
+func (ds *FvecsDataset) NumTrainVectors() int {
+	return ds.trainRows
+}
+
+func (ds *FvecsDataset) TestVectors() [][]float32 {
+	return loadFvecsFloat32(ds.queryFile)
+}
+
+func (ds *FvecsDataset) Neighbors() [][]int {
+	return loadIvecsNeighbors(ds.groundFile)
+}
+
+// TrainFilters and TestFilters are always empty: the Texmex format has no
+// concept of filter categories.
+func (ds *FvecsDataset) TrainFilters() []int {
+	return make([]int, 0)
+}
+
+func (ds *FvecsDataset) TestFilters() []int {
+	return make([]int, 0)
+}
+
+func (ds *FvecsDataset) StreamTrainData(chunks chan<- Batch, batchSize int, startOffset int, maxRecords int) {
+	cfg := &Config{BatchSize: batchSize, DatasetPrefetch: ds.prefetch}
+	loadFvecsStreaming(ds.trainFile, chunks, cfg, uint(startOffset), uint(maxRecords), nil)
+}
+
+// NewDatasetFromFile dispatches to the right Dataset implementation by file
+// extension: .h5/.hdf5 via NewHdf5Dataset, .fvecs/.bvecs via NewFvecsDataset.
+func NewDatasetFromFile(filePath string, multiVectorDimension int, filters bool, readerParallel int) Dataset {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".fvecs", ".bvecs":
+		return NewFvecsDataset(filePath, multiVectorDimension, filters, readerParallel)
+	default:
+		return NewHdf5Dataset(filePath, multiVectorDimension, filters, readerParallel)
+	}
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// GraphiteConfig holds configuration for streaming live benchmark metrics to
+// a Graphite/Carbon backend over the plaintext protocol, as an alternative
+// to --prometheus-push for teams that standardize on Graphite.
+type GraphiteConfig struct {
+	Enabled    bool
+	Address    string // host:port of the Carbon line-receiver
+	Protocol   string // "tcp" (default) or "udp"
+	Prefix     string
+	PushPeriod time.Duration
+}
+
+// GraphiteReporter streams qps, rolling mean/p99 latency, recall, NDCG, and
+// heap stats to a Graphite/Carbon backend at a fixed interval, over a single
+// long-lived connection that's redialed with backoff if it drops. It's
+// independent of LiveMetrics (the Prometheus scrape/push path), so it can be
+// enabled on its own for Graphite-only shops.
+type GraphiteReporter struct {
+	cfg  GraphiteConfig
+	tags string
+
+	mu      sync.Mutex
+	conn    net.Conn
+	queries int64
+	hist    *HDRHistogram
+	recall  float64
+	ndcg    float64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewGraphiteReporter builds a reporter that tags every metric name with
+// tags (e.g. ";api=grpc;dataset=sift1m;run_id=..."), matching the
+// Graphite-tagged "<name>;k=v;k=v" series naming convention.
+func NewGraphiteReporter(cfg GraphiteConfig, tags map[string]string) *GraphiteReporter {
+	var b strings.Builder
+	for k, v := range tags {
+		fmt.Fprintf(&b, ";%s=%s", k, v)
+	}
+
+	return &GraphiteReporter{
+		cfg:  cfg,
+		tags: b.String(),
+		hist: NewHDRHistogram(defaultHDRPrecisionBits, defaultHDRLowestTrackable, defaultHDRHighestTrackable),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+// RecordLatency folds one completed query's latency into the rolling window
+// the next flush reports mean/p99 from.
+func (g *GraphiteReporter) RecordLatency(latency time.Duration) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.queries++
+	g.hist.Record(latency)
+}
+
+// RecordRecall sets the recall/NDCG the next flush reports. Only the grpc
+// query path computes these today.
+func (g *GraphiteReporter) RecordRecall(recall, ndcg float64) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.recall = recall
+	g.ndcg = ndcg
+}
+
+// Start dials cfg.Address and begins flushing metrics every cfg.PushPeriod
+// in the background until Stop is called. A no-op if GraphiteConfig.Enabled
+// is false.
+func (g *GraphiteReporter) Start() {
+	if g == nil || !g.cfg.Enabled {
+		return
+	}
+
+	period := g.cfg.PushPeriod
+	if period <= 0 {
+		period = 10 * time.Second
+	}
+
+	go func() {
+		defer close(g.done)
+
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+
+		lastFlush := time.Now()
+		for {
+			select {
+			case <-g.stop:
+				g.flush(lastFlush)
+				return
+			case now := <-ticker.C:
+				g.flush(lastFlush)
+				lastFlush = now
+			}
+		}
+	}()
+}
+
+// Stop flushes one final snapshot and closes the connection. A no-op if
+// GraphiteConfig.Enabled is false.
+func (g *GraphiteReporter) Stop() {
+	if g == nil || !g.cfg.Enabled {
+		return
+	}
+
+	close(g.stop)
+	<-g.done
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.conn != nil {
+		g.conn.Close()
+		g.conn = nil
+	}
+}
+
+func (g *GraphiteReporter) flush(since time.Time) {
+	g.mu.Lock()
+	queries := g.queries
+	mean := g.hist.Mean()
+	p99 := g.hist.ValueAtPercentile(99)
+	recall := g.recall
+	ndcg := g.ndcg
+	g.queries = 0
+	g.hist = NewHDRHistogram(defaultHDRPrecisionBits, defaultHDRLowestTrackable, defaultHDRHighestTrackable)
+	g.mu.Unlock()
+
+	elapsed := time.Since(since).Seconds()
+	var qps float64
+	if elapsed > 0 {
+		qps = float64(queries) / elapsed
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	now := time.Now().Unix()
+	lines := []string{
+		g.line("qps", qps, now),
+		g.line("latency_mean_seconds", mean.Seconds(), now),
+		g.line("latency_p99_seconds", p99.Seconds(), now),
+		g.line("recall", recall, now),
+		g.line("ndcg", ndcg, now),
+		g.line("heap_alloc_bytes", float64(mem.HeapAlloc), now),
+		g.line("heap_inuse_bytes", float64(mem.HeapInuse), now),
+		g.line("heap_sys_bytes", float64(mem.HeapSys), now),
+	}
+
+	if err := g.send(lines); err != nil {
+		log.WithError(err).Warn("Failed to push metrics to Graphite")
+	}
+}
+
+func (g *GraphiteReporter) line(metric string, value float64, unixTs int64) string {
+	return fmt.Sprintf("%s.%s%s %f %d\n", g.cfg.Prefix, metric, g.tags, value, unixTs)
+}
+
+// send writes lines to the long-lived connection, dialing (or redialing, if
+// it's been dropped since the last flush) with a short backoff first.
+func (g *GraphiteReporter) send(lines []string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.conn == nil {
+		conn, err := g.dialWithBackoff()
+		if err != nil {
+			return err
+		}
+		g.conn = conn
+	}
+
+	if _, err := g.conn.Write([]byte(strings.Join(lines, ""))); err != nil {
+		g.conn.Close()
+		g.conn = nil
+		return err
+	}
+
+	return nil
+}
+
+func (g *GraphiteReporter) dialWithBackoff() (net.Conn, error) {
+	protocol := g.cfg.Protocol
+	if protocol == "" {
+		protocol = "tcp"
+	}
+
+	backoff := 100 * time.Millisecond
+	var lastErr error
+	for attempt := 0; attempt < 3; attempt++ {
+		conn, err := net.DialTimeout(protocol, g.cfg.Address, 5*time.Second)
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	return nil, fmt.Errorf("failed to dial graphite at %s after 3 attempts: %w", g.cfg.Address, lastErr)
+}
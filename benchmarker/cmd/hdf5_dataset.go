@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"sync"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/weaviate/hdf5"
 )
@@ -11,9 +13,11 @@ type Hdf5Dataset struct {
 	trainRows            int
 	multiVectorDimension int
 	useFilters           bool
+	readerParallel       int
+	schema               DatasetSchema
 }
 
-func NewHdf5Dataset(filePath string, multiVectorDimension int, filters bool) *Hdf5Dataset {
+func NewHdf5Dataset(filePath string, multiVectorDimension int, filters bool, readerParallel int) *Hdf5Dataset {
 	file, err := hdf5.OpenFile(filePath, hdf5.F_ACC_RDONLY)
 	if err != nil {
 		log.Fatalf("Error opening file: %v\n", err)
@@ -34,12 +38,20 @@ func NewHdf5Dataset(filePath string, multiVectorDimension int, filters bool) *Hd
 		dimension = int(extent[1])
 	}
 
+	if readerParallel < 1 {
+		readerParallel = 1
+	}
+
+	schema := readDatasetSchema(file, multiVectorDimension)
+
 	return &Hdf5Dataset{
 		file:                 file,
 		trainDimension:       dimension,
 		trainRows:            rows,
 		multiVectorDimension: multiVectorDimension,
 		useFilters:           filters,
+		readerParallel:       readerParallel,
+		schema:               schema,
 	}
 }
 
@@ -51,6 +63,7 @@ func (ds *Hdf5Dataset) TestFilters() []int {
 	if !ds.useFilters {
 		return make([]int, 0)
 	}
+	ds.warnIfMultipleFilterColumns()
 	return loadHdf5Categories(ds.file, "test_categories")
 }
 
@@ -58,18 +71,40 @@ func (ds *Hdf5Dataset) TrainFilters() []int {
 	if !ds.useFilters {
 		return make([]int, 0)
 	}
+	ds.warnIfMultipleFilterColumns()
 	return loadHdf5Categories(ds.file, "train_categories")
 }
 
+// warnIfMultipleFilterColumns is a stopgap for schema v2 files that declare
+// more than one filter column: only a single train_categories/test_categories
+// pair is wired up to Weaviate's where-filter so far, so surface what's being
+// dropped instead of silently ignoring it.
+func (ds *Hdf5Dataset) warnIfMultipleFilterColumns() {
+	if len(ds.schema.FilterColumns) > 1 {
+		log.Warnf("dataset schema declares %d filter columns %v, only train_categories/test_categories is currently loaded",
+			len(ds.schema.FilterColumns), ds.schema.FilterColumns)
+	}
+}
+
 func (ds *Hdf5Dataset) Neighbors() [][]int {
-	return loadHdf5Neighbors(ds.file, "neighbors")
+	switch ds.schema.NeighborLayout {
+	case NeighborLayoutRagged:
+		log.Fatalf("neighbor layout %q is not yet supported, add a loadHdf5NeighborsRagged reader", ds.schema.NeighborLayout)
+		return nil
+	default:
+		return loadHdf5Neighbors(ds.file, "neighbors")
+	}
 }
 
 func (ds *Hdf5Dataset) TestVectors() [][]float32 {
-	if ds.multiVectorDimension > 0 {
+	switch ds.schema.VectorEncoding {
+	case VectorEncodingColbert:
 		return loadHdf5Colbert(ds.file, "test", ds.multiVectorDimension)
-	} else {
+	case VectorEncodingDenseF32:
 		return loadHdf5Float32(ds.file, "test")
+	default:
+		log.Fatalf("vector encoding %q is not yet supported by TestVectors, add a reader for it", ds.schema.VectorEncoding)
+		return nil
 	}
 }
 
@@ -91,10 +126,13 @@ func (ds *Hdf5Dataset) StreamTrainData(chunks chan<- Batch, batchSize int, start
 	}
 	defer dataset.Close()
 
-	if ds.multiVectorDimension > 0 {
+	switch ds.schema.VectorEncoding {
+	case VectorEncodingColbert:
 		loadHdf5StreamingColbert(dataset, chunks, uint(batchSize), uint(startOffset), uint(maxRows), ds.multiVectorDimension, trainFilters)
-	} else {
-		loadHdf5Streaming(dataset, chunks, uint(batchSize), uint(startOffset), uint(maxRows), trainFilters)
+	case VectorEncodingDenseF32:
+		loadHdf5StreamingParallel(dataset, chunks, uint(batchSize), uint(startOffset), uint(maxRows), trainFilters, ds.readerParallel)
+	default:
+		log.Fatalf("vector encoding %q is not yet supported by StreamTrainData, add a reader for it", ds.schema.VectorEncoding)
 	}
 }
 
@@ -123,9 +161,28 @@ func getHDF5ByteSize(dataset *hdf5.Dataset) uint {
 	return byteSize
 }
 
-// Load a large dataset from an hdf5 file and stream it to Weaviate
-// startOffset and maxRecords are ignored if equal to 0
-func loadHdf5Streaming(dataset *hdf5.Dataset, chunks chan<- Batch, batchSize uint, startOffset uint, maxRecords uint, filters []int) {
+// hdf5StreamingJob describes one batch's row range and its position in
+// emission order, handed to the reader worker pool.
+type hdf5StreamingJob struct {
+	index     int
+	rowOffset uint
+	batchRows uint
+}
+
+type hdf5StreamingResult struct {
+	index int
+	batch Batch
+}
+
+// Load a large dataset from an hdf5 file and stream it to Weaviate.
+// startOffset and maxRecords are ignored if equal to 0. Reads are pipelined
+// across `parallel` reader workers, each owning an independent
+// dataspace/memspace pair (a dataspace's hyperslab selection is per-object
+// state in libhdf5, so sharing one across goroutines would race) and reusing
+// []float32/[]float64 buffers from a sync.Pool instead of allocating
+// batchSize*dimensions floats per batch. A single aggregator reorders worker
+// results so Offset is still emitted in ascending order, same as before.
+func loadHdf5StreamingParallel(dataset *hdf5.Dataset, chunks chan<- Batch, batchSize uint, startOffset uint, maxRecords uint, filters []int, parallel int) {
 	dataspace := dataset.Space()
 	dims, _, _ := dataspace.SimpleExtentDims()
 
@@ -148,68 +205,123 @@ func loadHdf5Streaming(dataset *hdf5.Dataset, chunks chan<- Batch, batchSize uin
 		i = startOffset
 	}
 
-	log.WithFields(log.Fields{"rows": rows, "dimensions": dimensions}).Printf(
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	log.WithFields(log.Fields{"rows": rows, "dimensions": dimensions, "readerParallel": parallel}).Printf(
 		"Reading HDF5 dataset")
 
-	memspace, err := hdf5.CreateSimpleDataspace([]uint{batchSize, dimensions}, []uint{batchSize, dimensions})
-	if err != nil {
-		log.Fatalf("Error creating memspace: %v", err)
-	}
-	defer memspace.Close()
+	float32Pool := &sync.Pool{New: func() interface{} { return make([]float32, batchSize*dimensions) }}
+	float64Pool := &sync.Pool{New: func() interface{} { return make([]float64, batchSize*dimensions) }}
+
+	jobs := make(chan hdf5StreamingJob, parallel*2)
+	results := make(chan hdf5StreamingResult, parallel*2)
 
-	for ; i < rows; i += batchSize {
+	var workers sync.WaitGroup
+	for w := 0; w < parallel; w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
 
-		batchRows := batchSize
-		// handle final smaller batch
-		if i+batchSize > rows {
-			batchRows = rows - i
-			memspace, err = hdf5.CreateSimpleDataspace([]uint{batchRows, dimensions}, []uint{batchRows, dimensions})
+			workerSpace := dataset.Space()
+			memspace, err := hdf5.CreateSimpleDataspace([]uint{batchSize, dimensions}, []uint{batchSize, dimensions})
 			if err != nil {
-				log.Fatalf("Error creating final memspace: %v", err)
+				log.Fatalf("Error creating memspace: %v", err)
 			}
-		}
+			defer memspace.Close()
 
-		offset := []uint{i, 0}
-		count := []uint{batchRows, dimensions}
+			for job := range jobs {
+				if job.batchRows != batchSize {
+					memspace, err = hdf5.CreateSimpleDataspace([]uint{job.batchRows, dimensions}, []uint{job.batchRows, dimensions})
+					if err != nil {
+						log.Fatalf("Error creating final memspace: %v", err)
+					}
+				}
 
-		if err := dataspace.SelectHyperslab(offset, nil, count, nil); err != nil {
-			log.Fatalf("Error selecting hyperslab: %v", err)
-		}
+				offset := []uint{job.rowOffset, 0}
+				count := []uint{job.batchRows, dimensions}
 
-		var chunkData [][]float32
+				if err := workerSpace.SelectHyperslab(offset, nil, count, nil); err != nil {
+					log.Fatalf("Error selecting hyperslab: %v", err)
+				}
 
-		if byteSize == 4 {
-			chunkData1D := make([]float32, batchRows*dimensions)
+				var chunkData [][]float32
 
-			if err := dataset.ReadSubset(&chunkData1D, memspace, dataspace); err != nil {
-				log.Printf("BatchRows = %d, i = %d, rows = %d", batchRows, i, rows)
-				log.Fatalf("Error reading subset: %v", err)
-			}
+				if byteSize == 4 {
+					buf := float32Pool.Get().([]float32)[:job.batchRows*dimensions]
 
-			chunkData = convert1DChunk[float32](chunkData1D, int(dimensions), int(batchRows))
+					if err := dataset.ReadSubset(&buf, memspace, workerSpace); err != nil {
+						log.Printf("BatchRows = %d, i = %d, rows = %d", job.batchRows, job.rowOffset, rows)
+						log.Fatalf("Error reading subset: %v", err)
+					}
 
-		} else if byteSize == 8 {
-			chunkData1D := make([]float64, batchRows*dimensions)
+					chunkData = convert1DChunk[float32](buf, int(dimensions), int(job.batchRows))
+					float32Pool.Put(buf[:batchSize*dimensions])
 
-			if err := dataset.ReadSubset(&chunkData1D, memspace, dataspace); err != nil {
-				log.Printf("BatchRows = %d, i = %d, rows = %d", batchRows, i, rows)
-				log.Fatalf("Error reading subset: %v", err)
-			}
+				} else if byteSize == 8 {
+					buf := float64Pool.Get().([]float64)[:job.batchRows*dimensions]
 
-			chunkData = convert1DChunk[float64](chunkData1D, int(dimensions), int(batchRows))
+					if err := dataset.ReadSubset(&buf, memspace, workerSpace); err != nil {
+						log.Printf("BatchRows = %d, i = %d, rows = %d", job.batchRows, job.rowOffset, rows)
+						log.Fatalf("Error reading subset: %v", err)
+					}
 
-		}
+					chunkData = convert1DChunk[float64](buf, int(dimensions), int(job.batchRows))
+					float64Pool.Put(buf[:batchSize*dimensions])
+				}
 
-		if (i+batchRows)%10000 == 0 {
-			log.Printf("Imported %d/%d rows", i+batchRows, rows)
-		}
+				filter := []int{}
+				if len(filters) > 0 {
+					filter = filters[job.rowOffset : job.rowOffset+job.batchRows]
+				}
 
-		filter := []int{}
-		if len(filters) > 0 {
-			filter = filters[i : i+batchRows]
+				results <- hdf5StreamingResult{
+					index: job.index,
+					batch: Batch{Vectors: chunkData, Offset: int(job.rowOffset), Filters: filter},
+				}
+			}
+		}()
+	}
+
+	go func() {
+		index := 0
+		for r := i; r < rows; r += batchSize {
+			batchRows := batchSize
+			if r+batchSize > rows {
+				batchRows = rows - r
+			}
+			jobs <- hdf5StreamingJob{index: index, rowOffset: r, batchRows: batchRows}
+			index++
 		}
+		close(jobs)
+	}()
+
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	// Reorder results by index so batches still arrive in ascending Offset
+	// order even though workers can finish them out of order.
+	pending := make(map[int]Batch)
+	next := 0
+	for r := range results {
+		pending[r.index] = r.batch
+		for {
+			batch, ok := pending[next]
+			if !ok {
+				break
+			}
+
+			if (uint(batch.Offset)+batchSize)%10000 == 0 {
+				log.Printf("Imported %d/%d rows", uint(batch.Offset)+batchSize, rows)
+			}
 
-		chunks <- Batch{Vectors: chunkData, Offset: int(i), Filters: filter}
+			chunks <- batch
+			delete(pending, next)
+			next++
+		}
 	}
 }
 
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"math"
+	"time"
+)
+
+// HDRHistogram is a log-bucketed latency recorder in the style of HDR
+// ("High Dynamic Range") histograms: each power-of-two octave is split into
+// 2^PrecisionBits logarithmically spaced sub-buckets, so every recorded value gets a bounded
+// relative error of roughly 2^-PrecisionBits regardless of its magnitude.
+// Recording is O(1) (one log2 plus an array increment), and percentile
+// queries only walk the small, fixed-size bucket array rather than sorting
+// every observation, which is what analyze's old sort.Slice/percentilePos
+// approach did.
+type HDRHistogram struct {
+	PrecisionBits    int      `json:"precisionBits"`
+	LowestTrackable  int64    `json:"lowestTrackableUs"`
+	HighestTrackable int64    `json:"highestTrackableUs"`
+	Counts           []uint64 `json:"counts"`
+	Count            uint64   `json:"count"`
+	SumUs            int64    `json:"sumUs"`
+}
+
+const (
+	// defaultHDRPrecisionBits gives each bucket roughly 0.8% relative
+	// resolution (2^-7), similar to the 2-3 significant decimal digits
+	// common HDR histogram defaults use.
+	defaultHDRPrecisionBits    = 7
+	defaultHDRLowestTrackable  = 1        // 1 microsecond
+	defaultHDRHighestTrackable = 60000000 // 60 seconds, in microseconds
+)
+
+// NewHDRHistogram builds an empty histogram covering
+// [lowestTrackableUs, highestTrackableUs] microseconds, with
+// 2^precisionBits sub-buckets per power-of-two octave.
+func NewHDRHistogram(precisionBits int, lowestTrackableUs, highestTrackableUs int64) *HDRHistogram {
+	if precisionBits <= 0 {
+		precisionBits = defaultHDRPrecisionBits
+	}
+	if lowestTrackableUs <= 0 {
+		lowestTrackableUs = defaultHDRLowestTrackable
+	}
+	if highestTrackableUs <= lowestTrackableUs {
+		highestTrackableUs = defaultHDRHighestTrackable
+	}
+
+	h := &HDRHistogram{
+		PrecisionBits:    precisionBits,
+		LowestTrackable:  lowestTrackableUs,
+		HighestTrackable: highestTrackableUs,
+	}
+	h.Counts = make([]uint64, h.bucketIndex(highestTrackableUs)+1)
+	return h
+}
+
+func (h *HDRHistogram) subBucketsPerOctave() float64 {
+	return float64(int64(1) << uint(h.PrecisionBits))
+}
+
+func (h *HDRHistogram) bucketIndex(valueUs int64) int {
+	if valueUs < h.LowestTrackable {
+		valueUs = h.LowestTrackable
+	}
+	if valueUs > h.HighestTrackable {
+		valueUs = h.HighestTrackable
+	}
+
+	idx := int(math.Log2(float64(valueUs)) * h.subBucketsPerOctave())
+	if idx < 0 {
+		idx = 0
+	}
+	return idx
+}
+
+func (h *HDRHistogram) bucketValue(idx int) int64 {
+	return int64(math.Exp2(float64(idx) / h.subBucketsPerOctave()))
+}
+
+// Record adds one observation of d to the histogram.
+func (h *HDRHistogram) Record(d time.Duration) {
+	valueUs := d.Microseconds()
+	h.SumUs += valueUs
+
+	idx := h.bucketIndex(valueUs)
+	if idx >= len(h.Counts) {
+		idx = len(h.Counts) - 1
+	}
+	h.Counts[idx]++
+	h.Count++
+}
+
+// ValueAtPercentile returns the smallest recorded bucket value v such that
+// at least percentile% of observations are <= v.
+func (h *HDRHistogram) ValueAtPercentile(percentile float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+
+	target := uint64(math.Ceil(percentile / 100 * float64(h.Count)))
+	if target < 1 {
+		target = 1
+	}
+
+	var cumulative uint64
+	for idx, count := range h.Counts {
+		cumulative += count
+		if cumulative >= target {
+			return time.Duration(h.bucketValue(idx)) * time.Microsecond
+		}
+	}
+
+	return time.Duration(h.HighestTrackable) * time.Microsecond
+}
+
+// Mean returns the arithmetic mean of every recorded observation. Unlike the
+// percentile buckets it's computed from the exact running sum, not
+// bucket midpoints.
+func (h *HDRHistogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return time.Duration(h.SumUs/int64(h.Count)) * time.Microsecond
+}
+
+// Merge folds other's counts into h, so histograms from multiple runs (or
+// parallel workers) can be combined before computing percentiles. Both
+// histograms must share the same bucket layout (PrecisionBits/trackable
+// range).
+func (h *HDRHistogram) Merge(other *HDRHistogram) {
+	if other == nil {
+		return
+	}
+	for idx, count := range other.Counts {
+		if idx < len(h.Counts) {
+			h.Counts[idx] += count
+		}
+	}
+	h.Count += other.Count
+	h.SumUs += other.SumUs
+}
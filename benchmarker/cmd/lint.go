@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// lintResultRecord mirrors the JSON shape the metrics-exporter file-watcher
+// decodes as MetricData (see metrics-exporter/main.go) - duplicated rather
+// than imported since the two binaries live in separate, unmanifested
+// modules with no shared dependency between them.
+type lintResultRecord struct {
+	API             string  `json:"api"`
+	Branch          string  `json:"branch"`
+	DatasetFile     string  `json:"dataset_file"`
+	EF              int     `json:"ef"`
+	EFConstruction  int     `json:"efConstruction"`
+	Limit           int     `json:"limit"`
+	MaxConnections  int     `json:"maxConnections"`
+	MeanLatency     float64 `json:"meanLatency"`
+	P99Latency      float64 `json:"p99Latency"`
+	QPS             float64 `json:"qps"`
+	Recall          float64 `json:"recall"`
+	Shards          int     `json:"shards"`
+	ImportTime      float64 `json:"importTime"`
+	HeapAllocBytes  float64 `json:"heap_alloc_bytes"`
+	HeapInuseBytes  float64 `json:"heap_inuse_bytes"`
+	HeapSysBytes    float64 `json:"heap_sys_bytes"`
+	QueriesTimedOut int     `json:"queries_timed_out"`
+	Timestamp       string  `json:"timestamp,omitempty"`
+}
+
+// lintIssue is one problem found in one record of one file.
+type lintIssue struct {
+	File    string `json:"file"`
+	Index   int    `json:"index"`
+	Message string `json:"message"`
+}
+
+// lintParamTuple identifies a benchmark configuration that should only ever
+// appear once across a set of result files - a second occurrence usually
+// means a CI run overwrote or duplicated an artifact.
+type lintParamTuple struct {
+	Branch         string
+	Dataset        string
+	EF             int
+	EFConstruction int
+	MaxConnections int
+	Limit          int
+}
+
+func lintDirectory(dir string, maxTagCardinality int) ([]lintIssue, error) {
+	var issues []lintIssue
+
+	seenTuples := make(map[lintParamTuple]string)
+	branches := make(map[string]bool)
+	datasets := make(map[string]bool)
+	apis := make(map[string]bool)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", path, err)
+		}
+
+		var records []lintResultRecord
+		if err := json.Unmarshal(content, &records); err != nil {
+			issues = append(issues, lintIssue{File: path, Index: -1, Message: fmt.Sprintf("invalid JSON: %v", err)})
+			return nil
+		}
+
+		for i, r := range records {
+			for _, msg := range lintRecord(r) {
+				issues = append(issues, lintIssue{File: path, Index: i, Message: msg})
+			}
+
+			branches[r.Branch] = true
+			datasets[r.DatasetFile] = true
+			apis[r.API] = true
+
+			tuple := lintParamTuple{
+				Branch:         r.Branch,
+				Dataset:        r.DatasetFile,
+				EF:             r.EF,
+				EFConstruction: r.EFConstruction,
+				MaxConnections: r.MaxConnections,
+				Limit:          r.Limit,
+			}
+			if first, ok := seenTuples[tuple]; ok {
+				issues = append(issues, lintIssue{
+					File:  path,
+					Index: i,
+					Message: fmt.Sprintf("duplicate (branch=%s, dataset=%s, ef=%d, efConstruction=%d, maxConnections=%d, limit=%d) already seen in %s",
+						tuple.Branch, tuple.Dataset, tuple.EF, tuple.EFConstruction, tuple.MaxConnections, tuple.Limit, first),
+				})
+			} else {
+				seenTuples[tuple] = path
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for name, cardinality := range map[string]int{"branch": len(branches), "dataset": len(datasets), "api": len(apis)} {
+		if cardinality > maxTagCardinality {
+			issues = append(issues, lintIssue{
+				File:    dir,
+				Index:   -1,
+				Message: fmt.Sprintf("%s tag cardinality %d exceeds budget of %d", name, cardinality, maxTagCardinality),
+			})
+		}
+	}
+
+	return issues, nil
+}
+
+// lintRecord checks a single record and returns a human-readable message
+// per problem found, empty if the record is clean.
+func lintRecord(r lintResultRecord) []string {
+	var messages []string
+
+	if r.API == "" {
+		messages = append(messages, "missing required field \"api\"")
+	}
+	if r.DatasetFile == "" {
+		messages = append(messages, "missing required field \"dataset_file\"")
+	}
+
+	if r.EF < 1 {
+		messages = append(messages, fmt.Sprintf("ef must be >= 1, got %d", r.EF))
+	}
+	if r.EFConstruction > 0 && r.EFConstruction < r.EF {
+		messages = append(messages, fmt.Sprintf("efConstruction (%d) must be >= ef (%d) for HNSW", r.EFConstruction, r.EF))
+	}
+
+	if r.Recall < 0 || r.Recall > 1 {
+		messages = append(messages, fmt.Sprintf("recall must be in [0,1], got %f", r.Recall))
+	}
+
+	if r.MeanLatency <= 0 {
+		messages = append(messages, fmt.Sprintf("meanLatency must be positive, got %f", r.MeanLatency))
+	}
+	if r.P99Latency <= 0 {
+		messages = append(messages, fmt.Sprintf("p99Latency must be positive, got %f", r.P99Latency))
+	}
+	if r.QPS <= 0 {
+		messages = append(messages, fmt.Sprintf("qps must be positive, got %f", r.QPS))
+	}
+
+	if r.Timestamp != "" {
+		if _, err := time.Parse(time.RFC3339, r.Timestamp); err != nil {
+			messages = append(messages, fmt.Sprintf("timestamp %q is not valid RFC3339: %v", r.Timestamp, err))
+		}
+	}
+
+	return messages
+}
+
+var (
+	lintOutputFormat      string
+	lintMaxTagCardinality int
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint <path>",
+	Short: "Validate benchmark result JSON files",
+	Long: `lint walks a directory of the result JSONs the metrics-exporter
+file-watcher consumes and checks each record for missing required fields,
+out-of-range values (recall, latencies, QPS, ef/efConstruction), unparseable
+timestamps, excessive branch/dataset/api tag cardinality, and duplicate
+(branch, dataset, ef, efConstruction, maxConnections, limit) tuples across
+files. Exits non-zero if any issues are found.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		issues, err := lintDirectory(args[0], lintMaxTagCardinality)
+		if err != nil {
+			fatal(err)
+		}
+
+		if lintOutputFormat == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(issues); err != nil {
+				fatal(err)
+			}
+		} else {
+			sort.Slice(issues, func(i, j int) bool { return issues[i].File < issues[j].File })
+			for _, issue := range issues {
+				fmt.Printf("%s[%d]: %s\n", issue.File, issue.Index, issue.Message)
+			}
+			if len(issues) == 0 {
+				fmt.Println("lint: no issues found")
+			} else {
+				fmt.Printf("lint: %d issue(s) found\n", len(issues))
+			}
+		}
+
+		if len(issues) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func initLint() {
+	rootCmd.AddCommand(lintCmd)
+	lintCmd.Flags().StringVar(&lintOutputFormat, "format", "text", "Output format, one of [text, json]")
+	lintCmd.Flags().IntVar(&lintMaxTagCardinality, "max-tag-cardinality", 1000,
+		"Maximum distinct values allowed for each of branch/dataset/api across all linted files")
+}
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
 	"math/rand"
 	"sync"
@@ -13,18 +12,17 @@ import (
 	"github.com/weaviate/weaviate-go-client/v4/weaviate"
 	weaviategrpc "github.com/weaviate/weaviate/grpc/generated/protocol/v1"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
 )
 
 func loadTrainData(ds Dataset, cfg *Config, offset uint, maxRows uint, updatePercent float32) {
-	chunks := make(chan Batch, 10)
+	chunks := make(chan Batch, cfg.ImportQueueDepth)
 	go func() {
 		ds.StreamTrainData(chunks, cfg.BatchSize, int(offset), int(maxRows))
 		close(chunks)
 	}()
 
 	var wg sync.WaitGroup
-	for i := 0; i < 8; i++ {
+	for i := 0; i < cfg.ImportWorkers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -32,18 +30,13 @@ func loadTrainData(ds Dataset, cfg *Config, offset uint, maxRows uint, updatePer
 			// Import workers will primary use the direct gRPC client
 			// If triggering deletes before import, we need to use the normal go client
 			grpcCtx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-			httpOption := grpc.WithInsecure()
-			if cfg.HttpScheme == "https" {
-				creds := credentials.NewTLS(&tls.Config{
-					InsecureSkipVerify: true,
-				})
-				httpOption = grpc.WithTransportCredentials(creds)
-			}
+			httpOption := buildGrpcTransportOption(cfg)
 			defer cancel()
-			opts := []retry.CallOption{
-				retry.WithBackoff(retry.BackoffExponential(100 * time.Millisecond)),
+			dialOptions := append([]grpc.DialOption{httpOption, grpc.WithUnaryInterceptor(retry.UnaryClientInterceptor(retryCallOptions(cfg)...))}, grpcTuningDialOptions(cfg)...)
+			if perRPC := grpcPerRPCDialOption(cfg); perRPC != nil {
+				dialOptions = append(dialOptions, perRPC)
 			}
-			grpcConn, err := grpc.DialContext(grpcCtx, cfg.Origin, httpOption, grpc.WithUnaryInterceptor(retry.UnaryClientInterceptor(opts...)))
+			grpcConn, err := grpc.DialContext(grpcCtx, cfg.Origin, dialOptions...)
 			if err != nil {
 				log.Fatalf("Did not connect: %v", err)
 			}
@@ -55,10 +48,10 @@ func loadTrainData(ds Dataset, cfg *Config, offset uint, maxRows uint, updatePer
 				if updatePercent > 0 {
 					if rand.Float32() < updatePercent {
 						deleteChunk(&chunk, weaviateClient, cfg)
-						writeChunk(&chunk, &grpcClient, cfg)
+						writeChunk(&chunk, &grpcClient, cfg, "", nil, "")
 					}
 				} else {
-					writeChunk(&chunk, &grpcClient, cfg)
+					writeChunk(&chunk, &grpcClient, cfg, "", nil, "")
 				}
 			}
 		}()
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+)
+
+// recallEWMAAlpha weights each new recall/NDCG observation against the
+// running average exposed on LiveMetrics, so the gauges track a moving
+// average instead of jumping to whatever the single most recent query saw.
+const recallEWMAAlpha = 0.1
+
+// LiveMetrics is the in-flight counterpart to BenchmarkMetrics: where that
+// type only ever holds final-value gauges pushed once after the run
+// completes, LiveMetrics is updated from processQueueHttp/processQueueGrpc
+// as queries complete, and is served live over "/metrics" (see
+// StartMetricsServer) whenever cfg.PrometheusConfig.ScrapePort is set. It's
+// constructed fresh for each ef/limit combination runQueries benchmarks, so
+// the current ef and limit are attached as ConstLabels rather than gauges.
+type LiveMetrics struct {
+	Registry *prometheus.Registry
+
+	QueryLatency *prometheus.HistogramVec
+	QueriesTotal *prometheus.CounterVec
+	GrpcErrors   *prometheus.CounterVec
+	InFlight     prometheus.Gauge
+	Recall       prometheus.Gauge
+	NDCG         prometheus.Gauge
+	QPS          prometheus.Gauge
+
+	recallSeen bool
+	ndcgSeen   bool
+
+	startedAt time.Time
+	completed int64
+}
+
+// NewLiveMetrics builds a fresh registry carrying one histogram/counter per
+// (api, status) pair, plus gauges for in-flight requests and recall/NDCG
+// moving averages. Buckets run from 100us to ~50s, with Prometheus native
+// histograms also enabled (NativeHistogramBucketFactor) so high-resolution
+// quantiles are available without choosing classic bucket boundaries ahead
+// of time.
+func NewLiveMetrics(labels prometheus.Labels) *LiveMetrics {
+	registry := prometheus.NewRegistry()
+
+	metrics := &LiveMetrics{
+		Registry: registry,
+		QueryLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:                            "weaviate_benchmark_query_latency_seconds",
+			Help:                            "Latency of individual benchmark queries in seconds",
+			ConstLabels:                     labels,
+			Buckets:                         prometheus.ExponentialBuckets(0.0001, 2, 20),
+			NativeHistogramBucketFactor:     1.1,
+			NativeHistogramMaxBucketNumber:  160,
+			NativeHistogramMinResetDuration: time.Hour,
+		}, []string{"api", "status"}),
+		QueriesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "weaviate_benchmark_queries_total",
+			Help:        "Total number of benchmark queries issued, by outcome",
+			ConstLabels: labels,
+		}, []string{"api", "status"}),
+		GrpcErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "weaviate_benchmark_grpc_errors_total",
+			Help:        "Total number of grpc query errors, by status code",
+			ConstLabels: labels,
+		}, []string{"code"}),
+		InFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "weaviate_benchmark_queries_in_flight",
+			Help:        "Number of benchmark queries currently awaiting a response",
+			ConstLabels: labels,
+		}),
+		Recall: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "weaviate_benchmark_recall_moving_average",
+			Help:        "Exponential moving average of recall across completed queries",
+			ConstLabels: labels,
+		}),
+		NDCG: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "weaviate_benchmark_ndcg_moving_average",
+			Help:        "Exponential moving average of NDCG across completed queries",
+			ConstLabels: labels,
+		}),
+		QPS: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "weaviate_benchmark_queries_per_second",
+			Help:        "Queries completed per second so far in the current run",
+			ConstLabels: labels,
+		}),
+		startedAt: time.Now(),
+	}
+
+	registry.MustRegister(
+		metrics.QueryLatency,
+		metrics.QueriesTotal,
+		metrics.GrpcErrors,
+		metrics.InFlight,
+		metrics.Recall,
+		metrics.NDCG,
+		metrics.QPS,
+	)
+
+	return metrics
+}
+
+// ObserveQuery records one completed query's outcome. status is "success",
+// "error", or "timeout".
+func (m *LiveMetrics) ObserveQuery(api, status string, latency time.Duration) {
+	if m == nil {
+		return
+	}
+	m.QueryLatency.WithLabelValues(api, status).Observe(latency.Seconds())
+	m.QueriesTotal.WithLabelValues(api, status).Inc()
+
+	completed := atomic.AddInt64(&m.completed, 1)
+	if elapsed := time.Since(m.startedAt).Seconds(); elapsed > 0 {
+		m.QPS.Set(float64(completed) / elapsed)
+	}
+}
+
+// ObserveGrpcError records one grpc query error under its status code,
+// whether or not it's ultimately retried.
+func (m *LiveMetrics) ObserveGrpcError(code codes.Code) {
+	if m == nil {
+		return
+	}
+	m.GrpcErrors.WithLabelValues(code.String()).Inc()
+}
+
+// IncInFlight/DecInFlight bracket a single query's round trip.
+func (m *LiveMetrics) IncInFlight() {
+	if m == nil {
+		return
+	}
+	m.InFlight.Inc()
+}
+
+func (m *LiveMetrics) DecInFlight() {
+	if m == nil {
+		return
+	}
+	m.InFlight.Dec()
+}
+
+// RecordRecall folds one query's recall/NDCG into the moving-average gauges.
+func (m *LiveMetrics) RecordRecall(recall, ndcg float64) {
+	if m == nil {
+		return
+	}
+
+	if !m.recallSeen {
+		m.Recall.Set(recall)
+		m.recallSeen = true
+	} else {
+		m.Recall.Set(recallEWMAAlpha*recall + (1-recallEWMAAlpha)*currentGaugeValue(m.Recall))
+	}
+
+	if !m.ndcgSeen {
+		m.NDCG.Set(ndcg)
+		m.ndcgSeen = true
+	} else {
+		m.NDCG.Set(recallEWMAAlpha*ndcg + (1-recallEWMAAlpha)*currentGaugeValue(m.NDCG))
+	}
+}
+
+// currentGaugeValue reads back a gauge's current value so RecordRecall can
+// fold it into the next EWMA step without keeping a second copy of the
+// running average outside the metric itself.
+func currentGaugeValue(g prometheus.Gauge) float64 {
+	var m dto.Metric
+	if err := g.Write(&m); err != nil {
+		return 0
+	}
+	return m.GetGauge().GetValue()
+}
+
+// StartMetricsServer starts an http.Server exposing registry on "/metrics"
+// via promhttp.Handler, listening on port in the background. The caller is
+// responsible for calling Shutdown once the benchmark run completes.
+func StartMetricsServer(port int, registry *prometheus.Registry) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry}))
+
+	srv := &http.Server{
+		Addr:    fmt.Sprintf(":%d", port),
+		Handler: mux,
+	}
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Error("Metrics scrape server stopped unexpectedly")
+		}
+	}()
+
+	log.WithField("port", port).Info("Serving live benchmark metrics on /metrics")
+
+	return srv
+}
+
+// StopMetricsServer shuts srv down with a short grace period, logging
+// (rather than failing the benchmark) if it doesn't stop cleanly.
+func StopMetricsServer(srv *http.Server) {
+	if srv == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.WithError(err).Warn("Failed to cleanly shut down metrics scrape server")
+	}
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// MetricsSink is a pluggable destination a completed benchmark run's
+// ResultsJSONBenchmark can be published to. Each concrete sink wraps one of
+// the ad hoc push functions below (InfluxDB, Pushgateway, remote_write) or a
+// new one (StatsD, a JSON-lines file), so --sink can fan a single run out to
+// as many destinations as a user's observability stack needs without the
+// call sites caring which.
+type MetricsSink interface {
+	Publish(ctx context.Context, result *ResultsJSONBenchmark) error
+	Close() error
+}
+
+// buildMetricsSinks constructs the sinks named by cfg.Sinks, in order.
+// Sinks that only need cfg at publish time (influxdb, pushgateway,
+// remote_write) are built eagerly; statsd/file sinks that hold a live
+// connection or file handle are constructed here too, so the caller can
+// defer closing them once for the whole run.
+func buildMetricsSinks(cfg *Config) ([]MetricsSink, error) {
+	names := cfg.Sinks
+	if cfg.PushGatewayURL != "" && !slices.Contains(names, "pushgateway") {
+		// --push-gateway predates --sink and is still documented to push on
+		// its own; fold it in here (instead of a direct pushResultToGateway
+		// call at each benchmark call site) so it's never published twice
+		// when a user also passes --sink pushgateway.
+		names = append(append([]string{}, names...), "pushgateway")
+	}
+
+	sinks := make([]MetricsSink, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case "influxdb":
+			sinks = append(sinks, &influxDBSink{cfg: cfg})
+		case "pushgateway":
+			sinks = append(sinks, &pushGatewaySink{cfg: cfg})
+		case "remote_write":
+			sinks = append(sinks, &remoteWriteSink{cfg: cfg})
+		case "statsd":
+			sink, err := newStatsDSink(cfg)
+			if err != nil {
+				return nil, fmt.Errorf("error building statsd sink: %w", err)
+			}
+			sinks = append(sinks, sink)
+		case "file":
+			sink, err := newJSONFileSink(cfg.SinkFile)
+			if err != nil {
+				return nil, fmt.Errorf("error building file sink: %w", err)
+			}
+			sinks = append(sinks, sink)
+		default:
+			return nil, fmt.Errorf("unrecognized --sink %q, must be one of [influxdb, pushgateway, remote_write, statsd, file]", name)
+		}
+	}
+	return sinks, nil
+}
+
+// publishToSinks fans a completed run out to every configured sink
+// concurrently. Each sink's error is isolated and logged on its own - one
+// misconfigured or unreachable sink never stops the others from receiving
+// the result.
+func publishToSinks(ctx context.Context, sinks []MetricsSink, result *ResultsJSONBenchmark) {
+	var wg sync.WaitGroup
+	for _, sink := range sinks {
+		wg.Add(1)
+		go func(sink MetricsSink) {
+			defer wg.Done()
+			if err := sink.Publish(ctx, result); err != nil {
+				log.WithError(err).WithField("sink", fmt.Sprintf("%T", sink)).Warn("Failed to publish benchmark result to metrics sink")
+			}
+		}(sink)
+	}
+	wg.Wait()
+}
+
+// closeMetricsSinks closes every sink, logging (rather than failing the
+// run on) any error - a sink's Close is cleanup, not something a benchmark
+// run should abort over.
+func closeMetricsSinks(sinks []MetricsSink) {
+	for _, sink := range sinks {
+		if err := sink.Close(); err != nil {
+			log.WithError(err).WithField("sink", fmt.Sprintf("%T", sink)).Warn("Failed to close metrics sink")
+		}
+	}
+}
+
+// influxDBSink adapts the existing PushMetricsToInfluxDB function to
+// MetricsSink.
+type influxDBSink struct {
+	cfg *Config
+}
+
+func (s *influxDBSink) Publish(ctx context.Context, result *ResultsJSONBenchmark) error {
+	return PushMetricsToInfluxDB(s.cfg, result)
+}
+
+func (s *influxDBSink) Close() error { return nil }
+
+// pushGatewaySink adapts the existing pushResultToGateway function to
+// MetricsSink.
+type pushGatewaySink struct {
+	cfg *Config
+}
+
+func (s *pushGatewaySink) Publish(ctx context.Context, result *ResultsJSONBenchmark) error {
+	return pushResultToGateway(s.cfg, result)
+}
+
+func (s *pushGatewaySink) Close() error { return nil }
+
+// remoteWriteSink adapts the existing PushMetricsToRemoteWrite function to
+// MetricsSink.
+type remoteWriteSink struct {
+	cfg *Config
+}
+
+func (s *remoteWriteSink) Publish(ctx context.Context, result *ResultsJSONBenchmark) error {
+	return PushMetricsToRemoteWrite(s.cfg, result)
+}
+
+func (s *remoteWriteSink) Close() error { return nil }
+
+// statsdSink publishes results as DogStatsD-flavored gauges (StatsD's line
+// protocol plus the `#tag:value` suffix DogStatsD/most modern agents
+// understand) over a single, held-open UDP socket.
+type statsdSink struct {
+	conn   net.Conn
+	prefix string
+	cfg    *Config
+}
+
+func newStatsDSink(cfg *Config) (*statsdSink, error) {
+	if cfg.StatsDAddress == "" {
+		return nil, fmt.Errorf("--statsd-address must be set to use the statsd sink")
+	}
+
+	conn, err := net.Dial("udp", cfg.StatsDAddress)
+	if err != nil {
+		return nil, fmt.Errorf("error dialing statsd at %s: %w", cfg.StatsDAddress, err)
+	}
+
+	return &statsdSink{conn: conn, prefix: cfg.StatsDPrefix, cfg: cfg}, nil
+}
+
+func (s *statsdSink) Publish(ctx context.Context, result *ResultsJSONBenchmark) error {
+	branch := s.cfg.LabelMap["branch"]
+	if branch == "" {
+		branch = "main"
+	}
+	tags := fmt.Sprintf("dataset:%s,api:%s,run_id:%s,branch:%s,ef:%d", result.Dataset, result.Api, result.RunID, branch, result.Ef)
+
+	var buf bytes.Buffer
+	gauge := func(name string, value float64) {
+		fmt.Fprintf(&buf, "%s.%s:%v|g|#%s\n", s.prefix, name, value, tags)
+	}
+
+	gauge("mean_latency_seconds", result.Mean)
+	gauge("p99_latency_seconds", result.P99Latency)
+	gauge("queries_per_second", result.QueriesPerSecond)
+	gauge("recall", result.Recall)
+	gauge("import_time_seconds", result.ImportTime)
+	gauge("heap_alloc_bytes", result.HeapAllocBytes)
+	gauge("heap_inuse_bytes", result.HeapInuseBytes)
+	gauge("heap_sys_bytes", result.HeapSysBytes)
+	gauge("ef_construction", float64(result.EfConstruction))
+	gauge("max_connections", float64(result.MaxConnections))
+	gauge("shards", float64(result.Shards))
+	gauge("parallelization", float64(result.Parallelization))
+	gauge("limit", float64(result.Limit))
+
+	if _, err := s.conn.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("error writing to statsd: %w", err)
+	}
+	return nil
+}
+
+func (s *statsdSink) Close() error {
+	return s.conn.Close()
+}
+
+// jsonFileSink appends each result as one JSON-lines record, so results
+// accumulate in a single append-only file across runs without needing any
+// external service at all.
+type jsonFileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func newJSONFileSink(path string) (*jsonFileSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("--sink-file must be set to use the file sink")
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening sink file: %w", err)
+	}
+
+	return &jsonFileSink{file: f}, nil
+}
+
+func (s *jsonFileSink) Publish(ctx context.Context, result *ResultsJSONBenchmark) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("error marshaling result for file sink: %w", err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(data); err != nil {
+		return fmt.Errorf("error writing to sink file: %w", err)
+	}
+	return nil
+}
+
+func (s *jsonFileSink) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,391 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// NumpyDataset implements the Dataset interface on top of plain NumPy .npy
+// files (or a single .npz zip bundle), for corpora that ship train.npy,
+// test.npy, neighbors.npy and optional train_categories.npy/test_categories.npy
+// instead of an ann-benchmarks.com style HDF5 file.
+type NumpyDataset struct {
+	dir     string
+	zipFile *zip.ReadCloser
+
+	train        *npyArray
+	test         *npyArray
+	neighbors    [][]int
+	trainFilters []int
+	testFilters  []int
+}
+
+// NewNumpyDataset opens path as either a directory containing train.npy/
+// test.npy/neighbors.npy (and optional *_categories.npy) or a single .npz
+// bundle containing the same arrays under those key names. Filters are only
+// loaded when useFilters is set, matching Hdf5Dataset's behavior.
+func NewNumpyDataset(path string, useFilters bool) *NumpyDataset {
+	ds := &NumpyDataset{}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		log.Fatalf("Error opening numpy dataset %q: %v", path, err)
+	}
+
+	if !info.IsDir() && strings.HasSuffix(strings.ToLower(path), ".npz") {
+		zr, err := zip.OpenReader(path)
+		if err != nil {
+			log.Fatalf("Error opening npz bundle %q: %v", path, err)
+		}
+		ds.zipFile = zr
+	} else {
+		ds.dir = path
+	}
+
+	train, err := ds.readArray("train")
+	if err != nil {
+		log.Fatalf("Error reading train array: %v", err)
+	}
+	ds.train = train
+
+	test, err := ds.readArray("test")
+	if err != nil {
+		log.Fatalf("Error reading test array: %v", err)
+	}
+	ds.test = test
+
+	neighborsArr, err := ds.readArray("neighbors")
+	if err != nil {
+		log.Fatalf("Error reading neighbors array: %v", err)
+	}
+	ds.neighbors = make([][]int, len(neighborsArr.rows))
+	for i, row := range neighborsArr.rows {
+		ds.neighbors[i] = make([]int, len(row))
+		for j, v := range row {
+			ds.neighbors[i][j] = int(v)
+		}
+	}
+
+	if useFilters {
+		ds.trainFilters = ds.readFilters("train_categories")
+		ds.testFilters = ds.readFilters("test_categories")
+	}
+
+	return ds
+}
+
+// open returns a reader for the array named name, either train.npy-style
+// files inside ds.dir or entries of the same name inside ds.zipFile.
+func (ds *NumpyDataset) open(name string) (io.ReadCloser, error) {
+	if ds.zipFile != nil {
+		for _, f := range ds.zipFile.File {
+			if strings.TrimSuffix(f.Name, ".npy") == name {
+				return f.Open()
+			}
+		}
+		return nil, fmt.Errorf("key %q not found in npz bundle", name)
+	}
+	return os.Open(filepath.Join(ds.dir, name+".npy"))
+}
+
+func (ds *NumpyDataset) readArray(name string) (*npyArray, error) {
+	f, err := ds.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readNpy(f)
+}
+
+func (ds *NumpyDataset) readFilters(name string) []int {
+	arr, err := ds.readArray(name)
+	if err != nil {
+		log.WithError(err).Warnf("no %s array found, continuing without filters", name)
+		return make([]int, 0)
+	}
+	filters := make([]int, len(arr.rows))
+	for i, row := range arr.rows {
+		if len(row) > 0 {
+			filters[i] = int(row[0])
+		}
+	}
+	return filters
+}
+
+func (ds *NumpyDataset) Close() {
+	if ds.zipFile != nil {
+		ds.zipFile.Close()
+	}
+}
+
+func (ds *NumpyDataset) TestVectors() [][]float32 {
+	return ds.test.rows
+}
+
+func (ds *NumpyDataset) Neighbors() [][]int {
+	return ds.neighbors
+}
+
+func (ds *NumpyDataset) TrainFilters() []int {
+	return ds.trainFilters
+}
+
+func (ds *NumpyDataset) TestFilters() []int {
+	return ds.testFilters
+}
+
+func (ds *NumpyDataset) Dimension() int {
+	if len(ds.train.rows) == 0 {
+		return 0
+	}
+	return len(ds.train.rows[0])
+}
+
+func (ds *NumpyDataset) NumTrainVectors() int {
+	return len(ds.train.rows)
+}
+
+func (ds *NumpyDataset) StreamTrainData(chunks chan<- Batch, batchSize int, startOffset int, maxRows int) {
+	rows := len(ds.train.rows)
+	end := rows
+	if maxRows > 0 && startOffset+maxRows < rows {
+		end = startOffset + maxRows
+	}
+
+	for i := startOffset; i < end; i += batchSize {
+		batchEnd := i + batchSize
+		if batchEnd > end {
+			batchEnd = end
+		}
+
+		filter := []int{}
+		if len(ds.trainFilters) > 0 {
+			filter = ds.trainFilters[i:batchEnd]
+		}
+
+		if batchEnd%10000 == 0 {
+			log.Printf("Imported %d/%d rows", batchEnd, rows)
+		}
+
+		chunks <- Batch{Vectors: ds.train.rows[i:batchEnd], Offset: i, Filters: filter}
+	}
+}
+
+// runNumpyBenchmark is the ann-benchmark entry point for --numpy-dir: it
+// drives a NumpyDataset through the generic Dataset-interface import path
+// (loadANNBenchmarksData) instead of the HDF5-specific one, then reuses the
+// regular runQueries/benchmarkANN query path since that's already format
+// agnostic.
+func runNumpyBenchmark(cfg *Config) {
+	dataset := NewNumpyDataset(cfg.NumpyDir, cfg.Filter)
+	defer dataset.Close()
+
+	// runQueries labels its output with cfg.BenchmarkFile; numpy runs don't
+	// set that flag, so mirror it from NumpyDir for the run label.
+	cfg.BenchmarkFile = cfg.NumpyDir
+
+	client := createClient(cfg)
+
+	importTime := 0 * time.Second
+
+	if !cfg.QueryOnly {
+		if !cfg.ExistingSchema {
+			createSchema(cfg, client)
+		}
+
+		log.WithFields(log.Fields{"index": cfg.IndexType, "efC": cfg.EfConstruction, "m": cfg.MaxConnections,
+			"shards": cfg.Shards, "distance": cfg.DistanceMetric, "dataset": cfg.NumpyDir}).Info("Starting numpy import")
+
+		if cfg.NumTenants > 0 {
+			importTime = loadANNBenchmarksDataMultiTenant(dataset, cfg, client)
+		} else {
+			importTime = loadANNBenchmarksData(dataset, cfg, client, 0)
+		}
+
+		sleepDuration := time.Duration(cfg.QueryDelaySeconds) * time.Second
+		log.Printf("Waiting for %s to allow for compaction etc\n", sleepDuration)
+		time.Sleep(sleepDuration)
+	}
+
+	runQueries(cfg, importTime, dataset.TestVectors(), dataset.Neighbors(), dataset.TrainFilters(), dataset.TestFilters())
+}
+
+// npyArray is a parsed .npy array, normalized to row-major float32 rows
+// regardless of the file's on-disk dtype or fortran_order.
+type npyArray struct {
+	rows [][]float32
+}
+
+var (
+	npyDescrRe   = regexp.MustCompile(`'descr':\s*'([^']+)'`)
+	npyFortranRe = regexp.MustCompile(`'fortran_order':\s*(True|False)`)
+	npyShapeRe   = regexp.MustCompile(`'shape':\s*\(([^)]*)\)`)
+)
+
+// readNpy parses a pure NumPy .npy stream: the magic \x93NUMPY, a one-byte
+// major/minor version, a header dict describing descr/fortran_order/shape,
+// then the raw array data in row-major (C) or column-major (Fortran) order.
+// Supported dtypes are float32, float64, int32 and int64, in either byte
+// order. We read the data with plain buffered reads rather than mmap'ing the
+// file, since every element already needs decoding (dtype conversion to
+// float32, and un-transposing fortran_order arrays) so there is no streaming
+// benefit and it keeps this dependency-free.
+func readNpy(r io.Reader) (*npyArray, error) {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, 6)
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, fmt.Errorf("error reading npy magic: %w", err)
+	}
+	if string(magic) != "\x93NUMPY" {
+		return nil, fmt.Errorf("not a numpy file (bad magic %q)", magic)
+	}
+
+	version := make([]byte, 2)
+	if _, err := io.ReadFull(br, version); err != nil {
+		return nil, fmt.Errorf("error reading npy version: %w", err)
+	}
+
+	var headerLen int
+	if version[0] == 1 {
+		lenBytes := make([]byte, 2)
+		if _, err := io.ReadFull(br, lenBytes); err != nil {
+			return nil, fmt.Errorf("error reading npy header length: %w", err)
+		}
+		headerLen = int(binary.LittleEndian.Uint16(lenBytes))
+	} else {
+		lenBytes := make([]byte, 4)
+		if _, err := io.ReadFull(br, lenBytes); err != nil {
+			return nil, fmt.Errorf("error reading npy header length: %w", err)
+		}
+		headerLen = int(binary.LittleEndian.Uint32(lenBytes))
+	}
+
+	headerBytes := make([]byte, headerLen)
+	if _, err := io.ReadFull(br, headerBytes); err != nil {
+		return nil, fmt.Errorf("error reading npy header: %w", err)
+	}
+
+	descr, fortranOrder, shape, err := parseNpyHeader(string(headerBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	var rowCount, colCount int
+	switch len(shape) {
+	case 1:
+		rowCount, colCount = shape[0], 1
+	case 2:
+		rowCount, colCount = shape[0], shape[1]
+	default:
+		return nil, fmt.Errorf("unsupported npy shape %v, only 1D/2D arrays are supported", shape)
+	}
+
+	order := binary.ByteOrder(binary.LittleEndian)
+	if strings.HasPrefix(descr, ">") {
+		order = binary.BigEndian
+	}
+	kind := strings.TrimLeft(descr, "<>=|")
+
+	total := rowCount * colCount
+	flat := make([]float32, total)
+	for i := 0; i < total; i++ {
+		v, err := readNpyValue(br, order, kind)
+		if err != nil {
+			return nil, fmt.Errorf("error reading npy element %d: %w", i, err)
+		}
+		flat[i] = v
+	}
+
+	rows := make([][]float32, rowCount)
+	for i := 0; i < rowCount; i++ {
+		rows[i] = make([]float32, colCount)
+		for j := 0; j < colCount; j++ {
+			if fortranOrder {
+				rows[i][j] = flat[j*rowCount+i]
+			} else {
+				rows[i][j] = flat[i*colCount+j]
+			}
+		}
+	}
+
+	return &npyArray{rows: rows}, nil
+}
+
+func readNpyValue(r io.Reader, order binary.ByteOrder, kind string) (float32, error) {
+	switch kind {
+	case "f4":
+		var bits uint32
+		if err := binary.Read(r, order, &bits); err != nil {
+			return 0, err
+		}
+		return math.Float32frombits(bits), nil
+	case "f8":
+		var bits uint64
+		if err := binary.Read(r, order, &bits); err != nil {
+			return 0, err
+		}
+		return float32(math.Float64frombits(bits)), nil
+	case "i4":
+		var v int32
+		if err := binary.Read(r, order, &v); err != nil {
+			return 0, err
+		}
+		return float32(v), nil
+	case "i8":
+		var v int64
+		if err := binary.Read(r, order, &v); err != nil {
+			return 0, err
+		}
+		return float32(v), nil
+	default:
+		return 0, fmt.Errorf("unsupported npy dtype %q", kind)
+	}
+}
+
+// parseNpyHeader extracts descr/fortran_order/shape out of a .npy header,
+// which is a Python dict literal rendered as ASCII, e.g.
+// "{'descr': '<f4', 'fortran_order': False, 'shape': (1000, 128), }".
+func parseNpyHeader(header string) (descr string, fortranOrder bool, shape []int, err error) {
+	m := npyDescrRe.FindStringSubmatch(header)
+	if m == nil {
+		return "", false, nil, fmt.Errorf("npy header missing descr: %q", header)
+	}
+	descr = m[1]
+
+	m = npyFortranRe.FindStringSubmatch(header)
+	if m == nil {
+		return "", false, nil, fmt.Errorf("npy header missing fortran_order: %q", header)
+	}
+	fortranOrder = m[1] == "True"
+
+	m = npyShapeRe.FindStringSubmatch(header)
+	if m == nil {
+		return "", false, nil, fmt.Errorf("npy header missing shape: %q", header)
+	}
+	for _, part := range strings.Split(m[1], ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		n, convErr := strconv.Atoi(part)
+		if convErr != nil {
+			return "", false, nil, fmt.Errorf("error parsing npy shape dimension %q: %w", part, convErr)
+		}
+		shape = append(shape, n)
+	}
+
+	return descr, fortranOrder, shape, nil
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	wv1 "github.com/weaviate/weaviate/grpc/generated/protocol/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// openLoopJob is one scheduled dispatch: the query to send and the wall
+// clock time it was scheduled to go out at, so a worker can compute response
+// time (now - scheduledAt) in addition to service time (the RPC's own
+// duration).
+type openLoopJob struct {
+	query       QueryWithNeighbors
+	scheduledAt time.Time
+}
+
+// benchmarkOpenLoop is the open-loop counterpart to benchmark: instead of
+// pre-partitioning queries across worker queues where each worker only
+// issues its next request once the previous one returns (closed loop, which
+// under saturation systematically hides tail latency - a slow worker just
+// stops generating load rather than queueing up the way real traffic
+// would), a single producer goroutine dispatches queries on a Poisson (or
+// fixed-interval) schedule at cfg.RateQPS onto a shared channel, and a pool
+// of cfg.Parallel workers drains it as fast as it can. Tracking response
+// time (including time spent queued) alongside service time is what
+// surfaces the coordinated-omission effect that motivates this mode.
+//
+// The repo's cfg.Mode field already selects the top-level subcommand
+// (random-vectors/ann-benchmark/...), so the load pattern switch lives on
+// its own cfg.QueryPattern field instead of overloading Mode.
+func benchmarkOpenLoop(cfg Config, getQueryFn func(className string) QueryWithNeighbors) Results {
+	serviceTimes := NewHDRHistogram(defaultHDRPrecisionBits, defaultHDRLowestTrackable, defaultHDRHighestTrackable)
+	responseTimes := NewHDRHistogram(defaultHDRPrecisionBits, defaultHDRLowestTrackable, defaultHDRHighestTrackable)
+	var recall []float64
+	var ndcg []float64
+	var timedOut int
+	var successful int
+	m := &sync.Mutex{}
+
+	queryTimeout := time.Duration(cfg.QueryTimeoutSeconds) * time.Second
+	if queryTimeout <= 0 {
+		queryTimeout = 30 * time.Second
+	}
+
+	runCtx := context.Background()
+	if cfg.RunDeadlineSeconds > 0 {
+		var runCancel context.CancelFunc
+		runCtx, runCancel = context.WithTimeout(runCtx, time.Duration(cfg.RunDeadlineSeconds)*time.Second)
+		defer runCancel()
+	}
+
+	httpOption := buildGrpcTransportOption(&cfg)
+
+	grpcCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	dialOptions := append([]grpc.DialOption{httpOption}, grpcTuningDialOptions(&cfg)...)
+	if perRPC := grpcPerRPCDialOption(&cfg); perRPC != nil {
+		dialOptions = append(dialOptions, perRPC)
+	}
+	if retryOpt := grpcRetryDialOption(&cfg); retryOpt != nil {
+		dialOptions = append(dialOptions, retryOpt)
+	}
+	grpcConn, err := grpc.DialContext(grpcCtx, cfg.Origin, dialOptions...)
+	if err != nil {
+		log.Fatalf("Did not connect: %v", err)
+	}
+	defer grpcConn.Close()
+	grpcClient := wv1.NewWeaviateClient(grpcConn)
+
+	rateQPS := cfg.RateQPS
+	if rateQPS <= 0 {
+		rateQPS = 100
+	}
+	interval := time.Duration(float64(time.Second) / rateQPS)
+
+	jobs := make(chan openLoopJob, cfg.Parallel*2)
+	rand.Seed(time.Now().UnixNano())
+
+	before := time.Now()
+
+	go func() {
+		defer close(jobs)
+		for i := 0; i < cfg.Queries; i++ {
+			if runCtx.Err() != nil {
+				return
+			}
+
+			select {
+			case jobs <- openLoopJob{query: getQueryFn(cfg.ClassName), scheduledAt: time.Now()}:
+			case <-runCtx.Done():
+				return
+			}
+
+			if cfg.RateDistribution == "fixed" {
+				time.Sleep(interval)
+			} else {
+				time.Sleep(time.Duration(rand.ExpFloat64() * float64(interval)))
+			}
+		}
+	}()
+
+	wg := &sync.WaitGroup{}
+	for w := 0; w < cfg.Parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if runCtx.Err() != nil {
+					return
+				}
+
+				searchRequest := &wv1.SearchRequest{}
+				if err := proto.Unmarshal(job.query.Query, searchRequest); err != nil {
+					log.Fatalf("Failed to unmarshal grpc query: %v", err)
+				}
+
+				serviceBefore := time.Now()
+				ctx, cancel := context.WithTimeout(runCtx, queryTimeout)
+				searchReply, err := grpcClient.Search(ctx, searchRequest)
+				ctxErr := ctx.Err()
+				cancel()
+				if err != nil {
+					if ctxErr != nil {
+						m.Lock()
+						timedOut++
+						m.Unlock()
+					} else {
+						fmt.Printf("ERROR: %v\n", err)
+					}
+					continue
+				}
+				serviceTook := time.Since(serviceBefore)
+				responseTook := time.Since(job.scheduledAt)
+
+				ids := make([]int, 0, len(searchReply.GetResults()))
+				for _, result := range searchReply.GetResults() {
+					ids = append(ids, intFromUUID(result.GetMetadata().Id))
+				}
+
+				var recallQuery, ndcgQuery float64
+				neighborLimit := min(cfg.Limit, len(job.query.Neighbors))
+				haveNeighbors := neighborLimit > 0
+				if haveNeighbors {
+					recallQuery = float64(len(intersection(ids, job.query.Neighbors[:neighborLimit]))) / float64(neighborLimit)
+					ndcgQuery = computeNDCG(ids, job.query.Neighbors[:neighborLimit], neighborLimit)
+				}
+
+				m.Lock()
+				successful++
+				serviceTimes.Record(serviceTook)
+				responseTimes.Record(responseTook)
+				if haveNeighbors {
+					recall = append(recall, recallQuery)
+					ndcg = append(ndcg, ndcgQuery)
+				}
+				m.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return analyzeOpenLoop(cfg, serviceTimes, responseTimes, time.Since(before), recall, ndcg, successful, timedOut)
+}
+
+// analyzeOpenLoop is analyze's open-loop counterpart: instead of sorting a
+// slice of per-query durations, it reads percentiles directly out of the
+// two histograms the workers recorded into as they went. Min/Max/Mean/
+// Percentiles keep the same (service-time) meaning analyze already gives
+// them, so closed- and open-loop Results stay comparable; ResponseTime* is
+// the open-loop-only, coordinated-omission-corrected counterpart.
+func analyzeOpenLoop(cfg Config, serviceTimes, responseTimes *HDRHistogram, total time.Duration, recall, ndcg []float64, successful, timedOut int) Results {
+	out := Results{PercentilesLabels: targetPercentiles, TimedOut: timedOut}
+
+	out.Total = cfg.Queries
+	out.Successful = successful
+	out.Failed = out.Total - successful
+	out.Parallelization = cfg.Parallel
+	out.Took = total
+	if total > 0 {
+		out.QueriesPerSecond = float64(successful) / total.Seconds()
+	}
+
+	out.Min = serviceTimes.ValueAtPercentile(0)
+	out.Max = serviceTimes.ValueAtPercentile(100)
+	out.Mean = serviceTimes.Mean()
+	out.LatencyHistogram = *serviceTimes
+
+	out.Percentiles = make([]time.Duration, len(targetPercentiles))
+	for i, percentile := range targetPercentiles {
+		out.Percentiles[i] = serviceTimes.ValueAtPercentile(float64(percentile))
+	}
+
+	out.ResponseTimeHistogram = *responseTimes
+	out.ResponseTimePercentiles = make([]time.Duration, len(targetPercentiles))
+	for i, percentile := range targetPercentiles {
+		out.ResponseTimePercentiles[i] = responseTimes.ValueAtPercentile(float64(percentile))
+	}
+
+	var sumRecall float64
+	for _, r := range recall {
+		sumRecall += r
+	}
+	if len(recall) > 0 {
+		out.Recall = sumRecall / float64(len(recall))
+	}
+
+	var sumNDCG float64
+	for _, n := range ndcg {
+		sumNDCG += n
+	}
+	if len(ndcg) > 0 {
+		out.NDCG = sumNDCG / float64(len(ndcg))
+	}
+
+	return out
+}
@@ -0,0 +1,373 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// openSearchClient is a thin wrapper around http.Client that knows how to
+// reach an OpenSearch/Elasticsearch cluster and authenticate against it,
+// mirroring the role createClient plays for the Weaviate gRPC path.
+type openSearchClient struct {
+	baseURL string
+	http    *http.Client
+	authz   string
+}
+
+func newOpenSearchClient(cfg *Config) *openSearchClient {
+	httpClient := &http.Client{Timeout: 300 * time.Second}
+	if cfg.HttpScheme == "https" {
+		httpClient.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
+
+	var authz string
+	if cfg.HttpAuth != "" {
+		if strings.Contains(cfg.HttpAuth, ":") {
+			authz = "Basic " + base64.StdEncoding.EncodeToString([]byte(cfg.HttpAuth))
+		} else {
+			authz = "Bearer " + cfg.HttpAuth
+		}
+	}
+
+	return &openSearchClient{
+		baseURL: fmt.Sprintf("%s://%s", cfg.HttpScheme, cfg.HttpOrigin),
+		http:    httpClient,
+		authz:   authz,
+	}
+}
+
+func (c *openSearchClient) do(method, path string, body []byte, contentType string) ([]byte, error) {
+	req, err := http.NewRequest(method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error building request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if c.authz != "" {
+		req.Header.Set("Authorization", c.authz)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error performing request: %w", err)
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	if res.StatusCode >= 300 {
+		return resBody, fmt.Errorf("unexpected status %d: %s", res.StatusCode, string(resBody))
+	}
+
+	return resBody, nil
+}
+
+// openSearchSpaceType maps the benchmarker's generic distance metric names
+// onto the space_type values OpenSearch's knn_vector field expects.
+func openSearchSpaceType(distanceMetric string) string {
+	switch distanceMetric {
+	case "cosine":
+		return "cosinesimil"
+	case "dot":
+		return "innerproduct"
+	case "l2-squared", "l2":
+		return "l2"
+	default:
+		return "l2"
+	}
+}
+
+// createOpenSearchIndex (re)creates cfg.ClassName as a knn-enabled index,
+// sized for cfg.Shards and configured with the same HNSW parameters used
+// on the Weaviate side so runs are comparable across engines.
+func createOpenSearchIndex(cfg *Config, client *openSearchClient, dimension int) error {
+	if _, err := client.do(http.MethodDelete, "/"+cfg.ClassName, nil, "application/json"); err != nil {
+		log.WithError(err).Debug("Index did not exist, continuing")
+	}
+
+	mapping := map[string]interface{}{
+		"settings": map[string]interface{}{
+			"index": map[string]interface{}{
+				"knn":                      true,
+				"number_of_shards":         cfg.Shards,
+				"number_of_replicas":       0,
+				"knn.algo_param.ef_search": cfg.EfConstruction,
+			},
+		},
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"vector": map[string]interface{}{
+					"type":      "knn_vector",
+					"dimension": dimension,
+					"method": map[string]interface{}{
+						"name":       "hnsw",
+						"engine":     "nmslib",
+						"space_type": openSearchSpaceType(cfg.DistanceMetric),
+						"parameters": map[string]interface{}{
+							"ef_construction": cfg.EfConstruction,
+							"m":               cfg.MaxConnections,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	body, err := json.Marshal(mapping)
+	if err != nil {
+		return fmt.Errorf("error marshaling index mapping: %w", err)
+	}
+
+	_, err = client.do(http.MethodPut, "/"+cfg.ClassName, body, "application/json")
+	return err
+}
+
+// bulkIndexBatch writes a single Batch using the OpenSearch/Elasticsearch
+// _bulk API, one index action + source document per vector.
+func bulkIndexBatch(cfg *Config, client *openSearchClient, batch Batch) error {
+	var buf bytes.Buffer
+
+	for i, vector := range batch.Vectors {
+		id := strconv.Itoa(i + batch.Offset + cfg.Offset)
+
+		action := map[string]interface{}{
+			"index": map[string]interface{}{
+				"_index": cfg.ClassName,
+				"_id":    id,
+			},
+		}
+		actionLine, err := json.Marshal(action)
+		if err != nil {
+			return fmt.Errorf("error marshaling bulk action: %w", err)
+		}
+
+		doc := map[string]interface{}{"vector": vector}
+		docLine, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("error marshaling bulk document: %w", err)
+		}
+
+		buf.Write(actionLine)
+		buf.WriteByte('\n')
+		buf.Write(docLine)
+		buf.WriteByte('\n')
+	}
+
+	resBody, err := client.do(http.MethodPost, "/_bulk", buf.Bytes(), "application/x-ndjson")
+	if err != nil {
+		return fmt.Errorf("error sending bulk request: %w", err)
+	}
+
+	var bulkResult struct {
+		Errors bool `json:"errors"`
+	}
+	if err := json.Unmarshal(resBody, &bulkResult); err == nil && bulkResult.Errors {
+		return fmt.Errorf("bulk request reported item-level errors: %s", string(resBody))
+	}
+
+	return nil
+}
+
+// importOpenSearch streams the training vectors out of dataset and bulk
+// indexes them into OpenSearch using cfg.Parallel worker goroutines,
+// mirroring the channel/worker-pool shape used for the Weaviate import path.
+func importOpenSearch(cfg *Config, client *openSearchClient, dataset Dataset) time.Duration {
+	if err := createOpenSearchIndex(cfg, client, dataset.Dimension()); err != nil {
+		log.Fatalf("Error creating OpenSearch index: %v", err)
+	}
+
+	startTime := time.Now()
+
+	chunks := make(chan Batch, cfg.Parallel)
+	go func() {
+		dataset.StreamTrainData(chunks, cfg.BatchSize, 0, dataset.NumTrainVectors())
+		close(chunks)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.Parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range chunks {
+				if err := bulkIndexBatch(cfg, client, batch); err != nil {
+					log.Fatalf("Error indexing batch: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := client.do(http.MethodPost, "/"+cfg.ClassName+"/_refresh", nil, "application/json"); err != nil {
+		log.WithError(err).Warn("Error refreshing index after import")
+	}
+
+	return time.Since(startTime)
+}
+
+// knnQueryBody builds a `knn` search query for a single test vector.
+func knnQueryBody(vector []float32, limit int) []byte {
+	query := map[string]interface{}{
+		"size": limit,
+		"query": map[string]interface{}{
+			"knn": map[string]interface{}{
+				"vector": map[string]interface{}{
+					"vector": vector,
+					"k":      limit,
+				},
+			},
+		},
+	}
+	body, _ := json.Marshal(query)
+	return body
+}
+
+// queryOpenSearch runs cfg.Queries nearest-neighbor searches against
+// OpenSearch spread across cfg.Parallel workers, closed-loop style like
+// benchmarkANN, and computes recall against the ground-truth neighbors.
+func queryOpenSearch(cfg Config, client *openSearchClient, testData [][]float32, neighbors [][]int) Results {
+	queues := make([][]int, cfg.Parallel)
+	for i := 0; i < cfg.Queries; i++ {
+		worker := i % cfg.Parallel
+		queues[worker] = append(queues[worker], i%len(testData))
+	}
+
+	var times []time.Duration
+	var recall []float64
+	m := &sync.Mutex{}
+
+	wg := &sync.WaitGroup{}
+	before := time.Now()
+
+	for _, queue := range queues {
+		wg.Add(1)
+		go func(queue []int) {
+			defer wg.Done()
+
+			for _, queryIndex := range queue {
+				body := knnQueryBody(testData[queryIndex], cfg.Limit)
+
+				queryBefore := time.Now()
+				resBody, err := client.do(http.MethodPost, "/"+cfg.ClassName+"/_search", body, "application/json")
+				took := time.Since(queryBefore)
+				if err != nil {
+					log.WithError(err).Warn("OpenSearch query failed")
+					continue
+				}
+
+				var result struct {
+					Hits struct {
+						Hits []struct {
+							ID string `json:"_id"`
+						} `json:"hits"`
+					} `json:"hits"`
+				}
+				if err := json.Unmarshal(resBody, &result); err != nil {
+					log.WithError(err).Warn("Error parsing OpenSearch query response")
+					continue
+				}
+
+				ids := make([]int, len(result.Hits.Hits))
+				for i, hit := range result.Hits.Hits {
+					id, err := strconv.Atoi(hit.ID)
+					if err == nil {
+						ids[i] = id
+					}
+				}
+
+				if queryIndex < len(neighbors) {
+					found := intersection(neighbors[queryIndex][:cfg.Limit], ids)
+					recall = append(recall, float64(len(found))/float64(cfg.Limit))
+				}
+
+				m.Lock()
+				times = append(times, took)
+				m.Unlock()
+			}
+		}(queue)
+	}
+
+	wg.Wait()
+
+	return analyze(cfg, times, time.Since(before), recall, nil, 0, nil, nil, nil)
+}
+
+// runOpenSearchBenchmark is the ann-benchmark entry point for
+// --indexType=opensearch-knn: it drives the whole HDF5 dataset through
+// OpenSearch's _bulk import and knn search APIs instead of Weaviate's
+// gRPC client, and writes out the same ResultsJSONBenchmark shape so
+// results stay comparable across engines.
+func runOpenSearchBenchmark(cfg *Config) {
+	runID := strconv.FormatInt(time.Now().Unix(), 10)
+
+	dataset := NewDatasetFromFile(cfg.BenchmarkFile, cfg.MultiVectorDimensions, cfg.Filter, cfg.ReaderParallel)
+	defer dataset.Close()
+
+	client := newOpenSearchClient(cfg)
+
+	var importTime time.Duration
+	if !cfg.QueryOnly {
+		log.WithFields(log.Fields{"index": cfg.IndexType, "efC": cfg.EfConstruction, "m": cfg.MaxConnections,
+			"shards": cfg.Shards, "distance": cfg.DistanceMetric, "dataset": cfg.BenchmarkFile}).Info("Starting OpenSearch import")
+		importTime = importOpenSearch(cfg, client, dataset)
+	}
+
+	result := queryOpenSearch(*cfg, client, dataset.TestVectors(), dataset.Neighbors())
+
+	log.WithFields(log.Fields{"mean": result.Mean, "qps": result.QueriesPerSecond, "recall": result.Recall,
+		"parallel": cfg.Parallel, "limit": cfg.Limit, "index": cfg.IndexType}).Info("Benchmark result")
+
+	benchResult := ResultsJSONBenchmark{
+		Api:              cfg.API,
+		Ef:               cfg.EfConstruction,
+		EfConstruction:   cfg.EfConstruction,
+		MaxConnections:   cfg.MaxConnections,
+		Mean:             result.Mean.Seconds(),
+		P99Latency:       result.Percentiles[len(result.Percentiles)-1].Seconds(),
+		QueriesPerSecond: result.QueriesPerSecond,
+		Shards:           cfg.Shards,
+		Parallelization:  cfg.Parallel,
+		Limit:            cfg.Limit,
+		ImportTime:       importTime.Seconds(),
+		RunID:            runID,
+		Dataset:          cfg.BenchmarkFile,
+		Recall:           result.Recall,
+		Histogram:        result.Histogram,
+	}
+
+	sinks, err := buildMetricsSinks(cfg)
+	if err != nil {
+		log.Fatalf("Error building metrics sinks: %v", err)
+	}
+	defer closeMetricsSinks(sinks)
+
+	publishToSinks(context.Background(), sinks, &benchResult)
+
+	data, err := json.MarshalIndent([]ResultsJSONBenchmark{benchResult}, "", "    ")
+	if err != nil {
+		log.Fatalf("Error marshaling benchmark results: %v", err)
+	}
+
+	os.Mkdir("./results", 0755)
+
+	if err := os.WriteFile(fmt.Sprintf("./results/%s.json", runID), data, 0644); err != nil {
+		log.Fatalf("Error writing benchmark results to file: %v", err)
+	}
+}
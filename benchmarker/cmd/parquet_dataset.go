@@ -3,26 +3,39 @@ package cmd
 import (
 	"errors"
 	"io"
+	"math"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/weaviate/weaviate/adapters/repos/db/vector/datasets"
 )
 
 type ParquetDataset struct {
-	hubDataset  *datasets.HubDataset
-	neighbors   [][]int
-	testVectors [][]float32
-	dimension   int
-	trainRows   int
+	hubDataset *datasets.HubDataset
+	datasetID  string
+	// extraVectorSubsets are additional hub dataset subsets read in lockstep
+	// with the primary one; their vectors are concatenated onto each row so
+	// multi-field/hybrid embeddings can be benchmarked as a single vector.
+	extraVectorSubsets []string
+	neighbors          [][]int
+	testVectors        [][]float32
+	trainFilters       []int
+	testFilters        []int
+	dimension          int
+	trainRows          int
+	filterColumn       string
+	filterSelectivity  float64
 }
 
-func NewParquetDataset(datasetID string, subset string, multiVectorDimension int, useFilters bool) *ParquetDataset {
-	if useFilters {
-		log.Fatalf("parquet datasets do not support filters (yet)")
-	}
-
+// NewParquetDataset opens datasetID/subset as a HuggingFace hub dataset.
+// filterColumn/filterSelectivity control TrainFilters/TestFilters the same
+// way train_categories/test_categories do for Hdf5Dataset; extraVectorSubsets
+// names additional subsets whose vectors are concatenated onto each row for
+// multi-vector groups. ColBERT-style per-row multi-vectors aren't supported
+// (that needs the subset itself to hold variable-length token vectors, which
+// the hub reader doesn't expose).
+func NewParquetDataset(datasetID string, subset string, multiVectorDimension int, extraVectorSubsets []string, filterColumn string, filterSelectivity float64) *ParquetDataset {
 	if multiVectorDimension > 0 {
-		log.Fatalf("parquet datasets do not support multi-vectors (yet)")
+		log.Fatalf("parquet datasets do not support ColBERT-style multi-vectors (yet)")
 	}
 
 	logger := log.New()
@@ -38,21 +51,65 @@ func NewParquetDataset(datasetID string, subset string, multiVectorDimension int
 		log.Fatalf("failed to read first chunk of training data to identify data dimension")
 	}
 	dimension := len(chunk.Vectors[0])
+	for range extraVectorSubsets {
+		// Hub subsets used for multi-vector groups are assumed to carry
+		// vectors of the same dimension as the primary one.
+		dimension += len(chunk.Vectors[0])
+	}
+
 	return &ParquetDataset{
-		hubDataset: hubDataset,
-		dimension:  dimension,
-		trainRows:  trainRows,
+		hubDataset:         hubDataset,
+		datasetID:          datasetID,
+		extraVectorSubsets: extraVectorSubsets,
+		dimension:          dimension,
+		trainRows:          trainRows,
+		filterColumn:       filterColumn,
+		filterSelectivity:  filterSelectivity,
 	}
 }
 
 func (ds *ParquetDataset) Close() {}
 
 func (ds *ParquetDataset) TestFilters() []int {
-	return make([]int, 0)
+	if ds.testFilters == nil {
+		ds.testFilters = ds.buildFilters(len(ds.TestVectors()))
+	}
+	return ds.testFilters
 }
 
 func (ds *ParquetDataset) TrainFilters() []int {
-	return make([]int, 0)
+	if ds.trainFilters == nil {
+		ds.trainFilters = ds.buildFilters(ds.trainRows)
+	}
+	return ds.trainFilters
+}
+
+// buildFilters generates a synthetic per-row filter tag (0 matching a where
+// filter of e.g. "category = 0") that hits roughly ds.filterSelectivity of
+// rows, so filtered-ANN recall/QPS can be benchmarked on any hub dataset
+// without an HDF5 conversion step. The hub dataset reader doesn't currently
+// expose arbitrary companion columns, so a configured filterColumn is only
+// logged for now rather than loaded as a natural label.
+func (ds *ParquetDataset) buildFilters(rows int) []int {
+	if ds.filterSelectivity <= 0 {
+		return make([]int, 0)
+	}
+
+	if ds.filterColumn != "" {
+		log.WithField("column", ds.filterColumn).Warn(
+			"parquet datasets cannot load a natural filter column yet, generating synthetic filter tags from --filter-selectivity instead")
+	}
+
+	buckets := int(math.Round(1 / ds.filterSelectivity))
+	if buckets < 1 {
+		buckets = 1
+	}
+
+	filters := make([]int, rows)
+	for i := range filters {
+		filters[i] = i % buckets
+	}
+	return filters
 }
 
 func (ds *ParquetDataset) loadTestData() {
@@ -97,15 +154,40 @@ func (ds *ParquetDataset) StreamTrainData(chunks chan<- Batch, batchSize int, st
 	}
 	defer trainReader.Close()
 
+	extraReaders := ds.openExtraVectorReaders(startRow, endRow, batchSize)
+	for _, reader := range extraReaders {
+		defer reader.Close()
+	}
+
+	trainFilters := ds.TrainFilters()
+
 	for {
 		chunk, err := trainReader.ReadNextChunk()
 		if err != nil && !errors.Is(err, io.EOF) {
 			log.Fatalf("failed while reading chunk of training data: %v", err)
 		}
+
+		vectors := chunk.Vectors
+		offsets := []int{0, len(chunk.Vectors[0])}
+		for _, reader := range extraReaders {
+			extraChunk, extraErr := reader.ReadNextChunk()
+			if extraErr != nil && !errors.Is(extraErr, io.EOF) {
+				log.Fatalf("failed while reading multi-vector group chunk: %v", extraErr)
+			}
+			vectors = concatVectorGroups(vectors, extraChunk.Vectors)
+			offsets = append(offsets, offsets[len(offsets)-1]+len(extraChunk.Vectors[0]))
+		}
+
+		filter := []int{}
+		if len(trainFilters) > 0 {
+			filter = trainFilters[chunk.RowOffset : chunk.RowOffset+len(chunk.Vectors)]
+		}
+
 		batch := Batch{
-			Vectors: chunk.Vectors,
-			Offset:  chunk.RowOffset,
-			Filters: make([]int, 0),
+			Vectors:       vectors,
+			Offset:        chunk.RowOffset,
+			Filters:       filter,
+			VectorOffsets: offsets,
 		}
 		// Logging here for compatibility with the HDF5 dataset.
 		if (batch.Offset+batchSize)%10000 == 0 {
@@ -118,6 +200,31 @@ func (ds *ParquetDataset) StreamTrainData(chunks chan<- Batch, batchSize int, st
 	}
 }
 
+// openExtraVectorReaders opens one DataReader per extra vector subset,
+// positioned at the same row range as the primary train reader so their
+// chunks can be read in lockstep and concatenated row-by-row.
+func (ds *ParquetDataset) openExtraVectorReaders(startRow, endRow, batchSize int) []*datasets.DataReader {
+	readers := make([]*datasets.DataReader, 0, len(ds.extraVectorSubsets))
+	for _, subset := range ds.extraVectorSubsets {
+		extraHub := datasets.NewHubDataset(ds.datasetID, subset, log.New())
+		reader, err := extraHub.NewDataReader(datasets.TrainSplit, startRow, endRow, batchSize)
+		if err != nil {
+			log.Fatalf("failed to open multi-vector subset %q: %v", subset, err)
+		}
+		readers = append(readers, reader)
+	}
+	return readers
+}
+
+// concatVectorGroups appends each vector in b onto the matching row of a.
+func concatVectorGroups(a, b [][]float32) [][]float32 {
+	out := make([][]float32, len(a))
+	for i := range a {
+		out[i] = append(append([]float32{}, a[i]...), b[i]...)
+	}
+	return out
+}
+
 func (ds *ParquetDataset) Dimension() int {
 	return ds.dimension
 }
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// profileSession is the CPU profile / execution trace a phase of an
+// ann-benchmark run (e.g. "import" or "query-ef16") can start via
+// --cpuProfile/--trace, plus the heap dump --memProfile takes once it
+// stops. A nil *profileSession, returned by startProfile when none of those
+// flags are set, is a no-op everywhere below - the same "disabled"
+// convention as LiveMetrics/GraphiteReporter/checkpointStore.
+type profileSession struct {
+	cfg       *Config
+	runID     string
+	phase     string
+	cpuFile   *os.File
+	traceFile *os.File
+}
+
+// startProfile begins the CPU profile and/or trace for phase if
+// --cpuProfile/--trace/--memProfile is set, writing output files named
+// <runID>-<phase>.{cpu.pprof,trace,heap.pprof} under the directory each
+// flag names, so they can be correlated with a throughput/recall regression
+// in that phase without instrumenting Weaviate itself.
+func startProfile(cfg *Config, runID string, phase string) *profileSession {
+	if cfg.CPUProfile == "" && cfg.MemProfile == "" && cfg.Trace == "" {
+		return nil
+	}
+
+	s := &profileSession{cfg: cfg, runID: runID, phase: phase}
+
+	if cfg.MemProfileRate > 0 {
+		runtime.MemProfileRate = cfg.MemProfileRate
+	}
+
+	if cfg.CPUProfile != "" {
+		f, err := os.Create(filepath.Join(cfg.CPUProfile, fmt.Sprintf("%s-%s.cpu.pprof", runID, phase)))
+		if err != nil {
+			log.Fatalf("Error creating cpu profile for phase %s: %v", phase, err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			log.Fatalf("Error starting cpu profile for phase %s: %v", phase, err)
+		}
+		s.cpuFile = f
+	}
+
+	if cfg.Trace != "" {
+		f, err := os.Create(filepath.Join(cfg.Trace, fmt.Sprintf("%s-%s.trace", runID, phase)))
+		if err != nil {
+			log.Fatalf("Error creating trace for phase %s: %v", phase, err)
+		}
+		if err := trace.Start(f); err != nil {
+			log.Fatalf("Error starting trace for phase %s: %v", phase, err)
+		}
+		s.traceFile = f
+	}
+
+	return s
+}
+
+// stop ends whatever CPU profile/trace startProfile began and writes a heap
+// profile if --memProfile is set.
+func (s *profileSession) stop() {
+	if s == nil {
+		return
+	}
+
+	if s.cpuFile != nil {
+		pprof.StopCPUProfile()
+		s.cpuFile.Close()
+	}
+
+	if s.traceFile != nil {
+		trace.Stop()
+		s.traceFile.Close()
+	}
+
+	if s.cfg.MemProfile != "" {
+		path := filepath.Join(s.cfg.MemProfile, fmt.Sprintf("%s-%s.heap.pprof", s.runID, s.phase))
+		f, err := os.Create(path)
+		if err != nil {
+			log.Fatalf("Error creating heap profile for phase %s: %v", s.phase, err)
+		}
+		defer f.Close()
+
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			log.Fatalf("Error writing heap profile for phase %s: %v", s.phase, err)
+		}
+	}
+}
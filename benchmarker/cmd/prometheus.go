@@ -15,6 +15,11 @@ type PrometheusConfig struct {
 	PushURL    string
 	JobName    string
 	PushPeriod time.Duration
+	// ScrapePort, when > 0, makes benchmark start a "/metrics" endpoint on
+	// this port for the duration of the run (see metrics_server.go), so
+	// in-flight latency/recall/NDCG can be watched live instead of only
+	// seeing the final-value gauges this file pushes once the run ends.
+	ScrapePort int
 }
 
 // BenchmarkMetrics holds the Prometheus metrics for the benchmark
@@ -184,3 +189,28 @@ func PushMetricsToPrometheus(cfg *Config, benchResult *ResultsJSONBenchmark) err
 
 	return nil
 }
+
+// pushRegistryToPrometheus gathers registry (typically the live LiveMetrics
+// registry a scrape server has been serving during the run, see
+// metrics_server.go) and pushes it to the same pushgateway PushMetricsToPrometheus
+// uses, so the real per-query histogram - and the quantiles it supports -
+// ends up in the gateway too, not just the final-value gauges above.
+func pushRegistryToPrometheus(cfg *Config, registry *prometheus.Registry) error {
+	if !cfg.PrometheusConfig.Enabled || cfg.PrometheusConfig.PushURL == "" {
+		return nil
+	}
+
+	if err := push.New(cfg.PrometheusConfig.PushURL, cfg.PrometheusConfig.JobName).
+		Gatherer(registry).
+		Push(); err != nil {
+		log.WithError(err).Error("Failed to push live metrics registry to Prometheus")
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"url": cfg.PrometheusConfig.PushURL,
+		"job": cfg.PrometheusConfig.JobName,
+	}).Info("Successfully pushed live metrics registry to Prometheus")
+
+	return nil
+}
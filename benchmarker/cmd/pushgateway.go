@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// pushGatewayBody renders a ResultsJSONBenchmark as Prometheus text
+// exposition format so it can be POSTed to a Pushgateway without pulling
+// in the full client_golang registry machinery.
+func pushGatewayBody(result *ResultsJSONBenchmark) []byte {
+	var b bytes.Buffer
+
+	metric := func(name, help string, value float64) {
+		fmt.Fprintf(&b, "# HELP weaviate_benchmark_%s %s\n", name, help)
+		fmt.Fprintf(&b, "# TYPE weaviate_benchmark_%s gauge\n", name)
+		fmt.Fprintf(&b, "weaviate_benchmark_%s{ef=\"%d\",api=\"%s\"} %v\n", name, result.Ef, result.Api, value)
+	}
+
+	metric("mean_latency_seconds", "Mean latency of benchmark queries in seconds", result.Mean)
+	metric("p99_latency_seconds", "P99 latency of benchmark queries in seconds", result.P99Latency)
+	metric("queries_per_second", "Queries per second during benchmark", result.QueriesPerSecond)
+	metric("recall", "Recall of benchmark queries", result.Recall)
+	metric("import_time_seconds", "Import time in seconds", result.ImportTime)
+	metric("heap_alloc_bytes", "Heap allocation in bytes", result.HeapAllocBytes)
+	metric("heap_inuse_bytes", "Heap in use in bytes", result.HeapInuseBytes)
+	metric("heap_sys_bytes", "Heap system in bytes", result.HeapSysBytes)
+	metric("queries_timed_out", "Number of queries that exceeded the configured query timeout", float64(result.QueriesTimedOut))
+
+	return b.Bytes()
+}
+
+// pushResultToGateway POSTs a completed ann-benchmark run to a Prometheus
+// Pushgateway so short-lived CI runs aren't missed by a scrape poll. The
+// branch and dataset grouping labels are appended to the gateway URL path,
+// mirroring the Pushgateway convention of additional grouping key segments.
+func pushResultToGateway(cfg *Config, result *ResultsJSONBenchmark) error {
+	if cfg.PushGatewayURL == "" {
+		return nil
+	}
+
+	branch := cfg.LabelMap["branch"]
+	if branch == "" {
+		branch = "main"
+	}
+	dataset := filepath.Base(result.Dataset)
+
+	url := fmt.Sprintf("%s/metrics/job/%s/instance/%s/branch/%s/dataset/%s",
+		strings.TrimSuffix(cfg.PushGatewayURL, "/"), cfg.PushGatewayJob, cfg.PushGatewayInstance, branch, dataset)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(pushGatewayBody(result)))
+	if err != nil {
+		return fmt.Errorf("error building pushgateway request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error pushing to pushgateway: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("pushgateway returned status %d", resp.StatusCode)
+	}
+
+	log.WithFields(log.Fields{"url": url, "ef": result.Ef}).Debug("Pushed benchmark result to pushgateway")
+	return nil
+}
@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"math"
+	"math/rand"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// queryDistributionModel is a low-rank fit of a reference dataset's train
+// vectors. randomVector samples uniformly from [-1,1]^d, which looks nothing
+// like real embedding manifolds; realisticRandomVector instead synthesizes
+// queries from this model so recall/latency numbers are representative of
+// production traffic against an HNSW index built on the same kind of data.
+type queryDistributionModel struct {
+	distribution   string
+	dimension      int
+	mean           []float64
+	stddev         []float64
+	components     [][]float64
+	singularValues []float64
+	trainSample    [][]float32
+}
+
+// fitQueryDistributionModel samples cfg.PCASampleSize train vectors out of
+// cfg.ReferenceDataset (an HDF5 file or a NumpyDataset directory/.npz) and
+// fits whatever cfg.QueryDistribution needs: per-dimension mean/stddev for
+// "gaussian", or the top cfg.PCAComponents principal components for
+// "pca"/"mixture".
+func fitQueryDistributionModel(cfg *Config) *queryDistributionModel {
+	ds := openReferenceDataset(cfg.ReferenceDataset)
+	defer ds.Close()
+
+	sampleSize := cfg.PCASampleSize
+	if sampleSize <= 0 {
+		sampleSize = 2000
+	}
+
+	sample := sampleTrainVectors(ds, sampleSize)
+	if len(sample) < 2 {
+		log.Fatalf("reference dataset %q yielded too few vectors (%d) to fit a query distribution",
+			cfg.ReferenceDataset, len(sample))
+	}
+
+	data := make([][]float64, len(sample))
+	for i, row := range sample {
+		data[i] = make([]float64, len(row))
+		for j, v := range row {
+			data[i][j] = float64(v)
+		}
+	}
+
+	mean, stddev := meanAndStddev(data)
+
+	var components [][]float64
+	var singularValues []float64
+	if cfg.QueryDistribution == "pca" || cfg.QueryDistribution == "mixture" {
+		k := cfg.PCAComponents
+		if k <= 0 {
+			k = 32
+		}
+		if k > len(mean) {
+			k = len(mean)
+		}
+		if k > len(data)-1 {
+			k = len(data) - 1
+		}
+		components, singularValues = computeTopPrincipalComponents(data, mean, k, 50)
+	}
+
+	return &queryDistributionModel{
+		distribution:   cfg.QueryDistribution,
+		dimension:      ds.Dimension(),
+		mean:           mean,
+		stddev:         stddev,
+		components:     components,
+		singularValues: singularValues,
+		trainSample:    sample,
+	}
+}
+
+// openReferenceDataset opens path as a Dataset, the same way ann-benchmark
+// would: a .h5/.hdf5 file via Hdf5Dataset, a .fvecs/.bvecs file via
+// FvecsDataset, anything else (a directory or .npz bundle) via NumpyDataset.
+func openReferenceDataset(path string) Dataset {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".h5", ".hdf5", ".fvecs", ".bvecs":
+		return NewDatasetFromFile(path, 0, false, 1)
+	default:
+		return NewNumpyDataset(path, false)
+	}
+}
+
+// sampleTrainVectors reads up to sampleSize train vectors out of ds, in file
+// order, for fitting a query distribution model.
+func sampleTrainVectors(ds Dataset, sampleSize int) [][]float32 {
+	if n := ds.NumTrainVectors(); n > 0 && n < sampleSize {
+		sampleSize = n
+	}
+
+	batchSize := sampleSize
+	if batchSize > 1000 {
+		batchSize = 1000
+	}
+
+	chunks := make(chan Batch, 4)
+	go func() {
+		ds.StreamTrainData(chunks, batchSize, 0, sampleSize)
+		close(chunks)
+	}()
+
+	vectors := make([][]float32, 0, sampleSize)
+	for batch := range chunks {
+		vectors = append(vectors, batch.Vectors...)
+	}
+
+	if len(vectors) > sampleSize {
+		vectors = vectors[:sampleSize]
+	}
+
+	return vectors
+}
+
+func meanAndStddev(data [][]float64) (mean, stddev []float64) {
+	n := len(data)
+	d := len(data[0])
+
+	mean = make([]float64, d)
+	for _, row := range data {
+		for j, v := range row {
+			mean[j] += v
+		}
+	}
+	for j := range mean {
+		mean[j] /= float64(n)
+	}
+
+	stddev = make([]float64, d)
+	for _, row := range data {
+		for j, v := range row {
+			diff := v - mean[j]
+			stddev[j] += diff * diff
+		}
+	}
+	for j := range stddev {
+		stddev[j] = math.Sqrt(stddev[j] / float64(n-1))
+	}
+
+	return mean, stddev
+}
+
+// computeTopPrincipalComponents fits the top k principal components of the
+// centered data via power iteration with deflation. Each component is found
+// by repeatedly multiplying by X^T X without ever forming that d x d
+// matrix (costly once d is in the hundreds); the converged direction is
+// then projected out of the centered data before the next component is
+// sought, so components come out mutually orthogonal.
+func computeTopPrincipalComponents(data [][]float64, mean []float64, k int, iterations int) (components [][]float64, singularValues []float64) {
+	n := len(data)
+	d := len(mean)
+
+	centered := make([][]float64, n)
+	for i, row := range data {
+		centered[i] = make([]float64, d)
+		for j, v := range row {
+			centered[i][j] = v - mean[j]
+		}
+	}
+
+	u := make([]float64, n)
+
+	for c := 0; c < k; c++ {
+		v := make([]float64, d)
+		for j := range v {
+			v[j] = rand.Float64()*2 - 1
+		}
+		normalizeInPlace(v)
+
+		for iter := 0; iter < iterations; iter++ {
+			for i, row := range centered {
+				var dot float64
+				for j, vj := range v {
+					dot += row[j] * vj
+				}
+				u[i] = dot
+			}
+
+			next := make([]float64, d)
+			for i, row := range centered {
+				ui := u[i]
+				for j, rv := range row {
+					next[j] += ui * rv
+				}
+			}
+
+			if normalizeInPlace(next) == 0 {
+				break
+			}
+			v = next
+		}
+
+		var sigmaSq float64
+		for i, row := range centered {
+			var dot float64
+			for j, vj := range v {
+				dot += row[j] * vj
+			}
+			u[i] = dot
+			sigmaSq += dot * dot
+		}
+		// sigma is the raw SVD singular value of the centered sample matrix;
+		// the per-axis stddev used to reconstruct a Gaussian in PCA space is
+		// sigma/sqrt(n-1), the same n-1 Bessel's correction as a sample
+		// stddev, not the singular value itself.
+		sigma := math.Sqrt(sigmaSq)
+		stddev := sigma
+		if n > 1 {
+			stddev = sigma / math.Sqrt(float64(n-1))
+		}
+
+		components = append(components, v)
+		singularValues = append(singularValues, stddev)
+
+		for i, ui := range u {
+			for j := range v {
+				centered[i][j] -= ui * v[j]
+			}
+		}
+	}
+
+	return components, singularValues
+}
+
+// normalizeInPlace L2-normalizes v and returns its pre-normalization norm (0
+// if v is the zero vector, signalling the caller to stop iterating rather
+// than divide by zero).
+func normalizeInPlace(v []float64) float64 {
+	var sumSq float64
+	for _, x := range v {
+		sumSq += x * x
+	}
+	norm := math.Sqrt(sumSq)
+	if norm == 0 {
+		return 0
+	}
+	for i := range v {
+		v[i] /= norm
+	}
+	return norm
+}
+
+// realisticRandomVector synthesizes a query vector from a fitted
+// queryDistributionModel rather than sampling uniformly, per
+// --query-distribution. "gaussian" samples each dimension independently from
+// the reference dataset's per-dimension mean/stddev. "pca" samples
+// mean + Σ z_i*σ_i*v_i + ε with z_i ~ N(0,1). "mixture" does the same but
+// perturbs around a random training vector instead of the dataset mean, so
+// queries land close to but not on top of real training points.
+func realisticRandomVector(model *queryDistributionModel, noiseStddev float64, normalize bool) []float32 {
+	var vec []float64
+
+	switch model.distribution {
+	case "gaussian":
+		vec = gaussianVector(model.mean, model.stddev)
+	case "pca":
+		vec = pcaVector(model.mean, model.components, model.singularValues, noiseStddev)
+	case "mixture":
+		center := model.trainSample[rand.Intn(len(model.trainSample))]
+		centerF64 := make([]float64, len(center))
+		for i, v := range center {
+			centerF64[i] = float64(v)
+		}
+		vec = pcaVector(centerF64, model.components, model.singularValues, noiseStddev)
+	default:
+		return randomVector(model.dimension)
+	}
+
+	if normalize {
+		normalizeInPlace(vec)
+	}
+
+	out := make([]float32, len(vec))
+	for i, v := range vec {
+		out[i] = float32(v)
+	}
+	return out
+}
+
+func gaussianVector(mean, stddev []float64) []float64 {
+	vec := make([]float64, len(mean))
+	for i := range vec {
+		vec[i] = mean[i] + rand.NormFloat64()*stddev[i]
+	}
+	return vec
+}
+
+// pcaVector synthesizes center + Σ z_i*σ_i*v_i + ε, z_i ~ N(0,1), ε isotropic
+// Gaussian noise with the given stddev.
+func pcaVector(center []float64, components [][]float64, singularValues []float64, noiseStddev float64) []float64 {
+	vec := make([]float64, len(center))
+	copy(vec, center)
+
+	for i, component := range components {
+		scale := rand.NormFloat64() * singularValues[i]
+		for j, cv := range component {
+			vec[j] += scale * cv
+		}
+	}
+
+	if noiseStddev > 0 {
+		for j := range vec {
+			vec[j] += rand.NormFloat64() * noiseStddev
+		}
+	}
+
+	return vec
+}
@@ -39,6 +39,10 @@ var randomTextCmd = &cobra.Command{
 		}
 		result := benchmarkNearText(cfg)
 		result.WriteTextTo(os.Stdout)
+
+		if result.Interrupted {
+			os.Exit(1)
+		}
 	},
 }
 
@@ -44,6 +44,76 @@ func initRandomVectors() {
 		"httpOrigin", "localhost:8080", "The http origin for Weaviate (without http scheme)")
 	randomVectorsCmd.PersistentFlags().StringVar(&globalConfig.HttpScheme,
 		"httpScheme", "http", "The http scheme (http or https)")
+	randomVectorsCmd.PersistentFlags().StringVar(&globalConfig.QueryDistribution,
+		"query-distribution", "uniform", "Query vector generator, one of [uniform, gaussian, pca, mixture]; all but uniform require --reference-dataset")
+	randomVectorsCmd.PersistentFlags().StringVar(&globalConfig.ReferenceDataset,
+		"reference-dataset", "", "Path to an HDF5 file or a NumpyDataset directory/.npz used to fit --query-distribution; --dimensions is ignored when this is set")
+	randomVectorsCmd.PersistentFlags().IntVar(&globalConfig.PCAComponents,
+		"pca-components", 32, "Number of principal components to fit for the pca/mixture query distributions")
+	randomVectorsCmd.PersistentFlags().IntVar(&globalConfig.PCASampleSize,
+		"pca-sample-size", 2000, "Number of reference vectors sampled to fit --query-distribution")
+	randomVectorsCmd.PersistentFlags().Float64Var(&globalConfig.QueryNoiseStddev,
+		"query-noise-stddev", 0.01, "Stddev of isotropic Gaussian noise added to each synthesized pca/mixture query vector")
+	randomVectorsCmd.PersistentFlags().BoolVar(&globalConfig.NormalizeQueries,
+		"normalize-queries", false, "L2-normalize synthesized query vectors, for cosine-space embeddings")
+	randomVectorsCmd.PersistentFlags().StringVar(&globalConfig.QueryPattern,
+		"query-pattern", "closed", "Query load pattern, one of [closed, open]; open dispatches queries on a schedule independent of response time and also reports response-time (queueing-inclusive) percentiles, avoiding the closed-loop coordinated-omission problem")
+	randomVectorsCmd.PersistentFlags().Float64Var(&globalConfig.RateQPS,
+		"rate-qps", 100, "Target queries/second for --query-pattern=open")
+	randomVectorsCmd.PersistentFlags().StringVar(&globalConfig.RateDistribution,
+		"rate-distribution", "poisson", "Inter-query arrival schedule for --query-pattern=open, one of [poisson, fixed]")
+	randomVectorsCmd.PersistentFlags().IntVar(&globalConfig.PrometheusConfig.ScrapePort,
+		"prometheus-scrape-port", 0, "If set, serve live per-query latency/recall/NDCG metrics on this port's /metrics endpoint for the duration of the run (default disabled)")
+	randomVectorsCmd.PersistentFlags().BoolVar(&globalConfig.PrometheusConfig.Enabled,
+		"prometheus-push", false, "Also push the live metrics registry (with real latency histograms) to --prometheus-push-url once the run completes")
+	randomVectorsCmd.PersistentFlags().StringVar(&globalConfig.PrometheusConfig.PushURL,
+		"prometheus-push-url", "", "Prometheus Pushgateway URL for --prometheus-push")
+	randomVectorsCmd.PersistentFlags().StringVar(&globalConfig.PrometheusConfig.JobName,
+		"prometheus-job", "weaviate-benchmarker", "Job name to use when pushing with --prometheus-push")
+	randomVectorsCmd.PersistentFlags().IntVar(&globalConfig.MaxRetries,
+		"max-retries", 0, "Number of times to retry a grpc query that fails with a retryable status code (default 0, disabled)")
+	randomVectorsCmd.PersistentFlags().StringVar(&globalConfig.RetryableCodes,
+		"retryable-codes", "", "Comma-separated grpc status codes to retry on, e.g. \"Unavailable,ResourceExhausted\" (default: Unavailable, DeadlineExceeded, ResourceExhausted)")
+	randomVectorsCmd.PersistentFlags().BoolVar(&globalConfig.GraphiteConfig.Enabled,
+		"graphite-push", false, "Stream live qps/latency/recall/NDCG/heap metrics to a Graphite/Carbon backend for the duration of the run")
+	randomVectorsCmd.PersistentFlags().StringVar(&globalConfig.GraphiteConfig.Address,
+		"graphite-address", "", "host:port of the Carbon line-receiver for --graphite-push")
+	randomVectorsCmd.PersistentFlags().StringVar(&globalConfig.GraphiteConfig.Protocol,
+		"graphite-protocol", "tcp", "Protocol to speak to --graphite-address with, one of [tcp, udp]")
+	randomVectorsCmd.PersistentFlags().StringVar(&globalConfig.GraphiteConfig.Prefix,
+		"graphite-prefix", "weaviate_benchmark", "Metric name prefix for --graphite-push")
+	randomVectorsCmd.PersistentFlags().DurationVar(&globalConfig.GraphiteConfig.PushPeriod,
+		"graphite-push-period", 10*time.Second, "How often to flush metrics to --graphite-address")
+	randomVectorsCmd.PersistentFlags().BoolVar(&globalConfig.CollectServerMetrics,
+		"collect-server-metrics", false, "Sample server-side HNSW insert queue depth, tombstones, object count, and LSM segment count for the duration of the run and attach them to the results")
+	randomVectorsCmd.PersistentFlags().StringVar(&globalConfig.PrometheusQueryURL,
+		"prometheus-query-url", "", "Prometheus server URL to query for --collect-server-metrics (default: scrape Weaviate's own :2112/metrics endpoint directly)")
+	randomVectorsCmd.PersistentFlags().IntVar(&globalConfig.ServerMetricsIntervalSec,
+		"server-metrics-interval-seconds", 5, "How often to sample server metrics for --collect-server-metrics")
+	randomVectorsCmd.PersistentFlags().StringVar(&globalConfig.GrpcCAFile,
+		"grpcCAFile", "", "PEM-encoded CA bundle to verify the gRPC server certificate against when --httpScheme=https (default: the system CA pool)")
+	randomVectorsCmd.PersistentFlags().StringVar(&globalConfig.GrpcClientCertFile,
+		"grpcClientCertFile", "", "PEM-encoded client certificate for mTLS, requires --grpcClientKeyFile (default disabled)")
+	randomVectorsCmd.PersistentFlags().StringVar(&globalConfig.GrpcClientKeyFile,
+		"grpcClientKeyFile", "", "PEM-encoded client private key for mTLS, requires --grpcClientCertFile (default disabled)")
+	randomVectorsCmd.PersistentFlags().BoolVar(&globalConfig.GrpcTLSSkipVerify,
+		"grpcTLSSkipVerify", false, "Skip verifying the gRPC server certificate when --httpScheme=https; mutually exclusive with --grpcCAFile, which it would otherwise silently ignore")
+	randomVectorsCmd.PersistentFlags().StringVar(&globalConfig.GrpcAuthToken,
+		"grpcAuthToken", "", "Bearer token sent as per-RPC credentials on the gRPC query connection, for clusters that enforce auth on their gRPC port (default disabled)")
+	randomVectorsCmd.PersistentFlags().IntVar(&globalConfig.GrpcKeepaliveTimeSeconds,
+		"grpcKeepaliveTime", 0, "Send a gRPC keepalive ping after this many seconds of inactivity, so long benchmarks don't lose an idle connection to a proxy or load balancer (default disabled)")
+	randomVectorsCmd.PersistentFlags().IntVar(&globalConfig.GrpcKeepaliveTimeout,
+		"grpcKeepaliveTimeout", 20, "Seconds to wait for a keepalive ping ack before considering the connection dead (only applies when --grpcKeepaliveTime is set)")
+	randomVectorsCmd.PersistentFlags().BoolVar(&globalConfig.GrpcKeepaliveNoStream,
+		"grpcKeepalivePermitWithoutStream", false, "Send keepalive pings even when there are no in-flight RPCs (only applies when --grpcKeepaliveTime is set)")
+	randomVectorsCmd.PersistentFlags().IntVar(&globalConfig.GrpcRetryMaxAttempts,
+		"grpcRetryMaxAttempts", 0, "Max gRPC-level retry attempts for transient errors, on top of the manual retry loop already used for query RPCs (default disabled: rely on the query-level --max-retries)")
+	randomVectorsCmd.PersistentFlags().IntVar(&globalConfig.GrpcRetryPerTryTimeoutSec,
+		"grpcRetryPerTryTimeout", 0, "Per-attempt timeout in seconds for --grpcRetryMaxAttempts (default: no per-attempt timeout beyond the RPC's own context deadline)")
+	randomVectorsCmd.PersistentFlags().IntVar(&globalConfig.GrpcRetryBackoffBaseMs,
+		"grpcRetryBackoffBaseMs", 100, "Base exponential backoff in milliseconds between gRPC-level retry attempts")
+	randomVectorsCmd.PersistentFlags().IntVar(&globalConfig.GrpcRetryBackoffMaxMs,
+		"grpcRetryBackoffMaxMs", 0, "Cap on the exponential backoff between gRPC-level retry attempts (default disabled: backoff grows unbounded with attempt count)")
 }
 
 var randomVectorsCmd = &cobra.Command{
@@ -65,6 +135,17 @@ var randomVectorsCmd = &cobra.Command{
 		client := createClient(&cfg)
 		cfg.Dimensions = getDimensions(cfg, client)
 
+		if cfg.QueryDistribution != "" && cfg.QueryDistribution != "uniform" {
+			if cfg.ReferenceDataset == "" {
+				log.Fatalf("--query-distribution=%s requires --reference-dataset", cfg.QueryDistribution)
+			}
+
+			cfg.QueryModel = fitQueryDistributionModel(&cfg)
+			cfg.Dimensions = cfg.QueryModel.dimension
+			log.WithFields(log.Fields{"distribution": cfg.QueryDistribution, "dimension": cfg.Dimensions,
+				"components": len(cfg.QueryModel.components)}).Info("Fitted query distribution from reference dataset")
+		}
+
 		var result Results
 
 		if cfg.QueryDuration > 0 {
@@ -127,6 +208,14 @@ func nearVectorQueryJSONGraphQL(className string, vec []float32, limit int, wher
 }`, className, limit, string(vecJSON), whereFilter))
 }
 
+func nearVectorQueryJSONRest(className string, vec []float32, limit int) []byte {
+	vecJSON, _ := json.Marshal(vec)
+	return []byte(fmt.Sprintf(`{
+		"nearVector":{"vector":%s},
+		"limit":%d
+}`, string(vecJSON), limit))
+}
+
 func encodeVector(fs []float32) []byte {
 	buf := make([]byte, len(fs)*4)
 	for i, f := range fs {
@@ -197,14 +286,26 @@ func nearVectorQueryGrpc(cfg *Config, vec []float32, tenant string, filter int)
 	}
 
 	if filter >= 0 {
-		searchRequest.Filters = &weaviategrpc.Filters{
-			TestValue: &weaviategrpc.Filters_ValueText{
-				ValueText: strconv.Itoa(filter),
-			},
-			On:       []string{"category"},
-			Operator: weaviategrpc.Filters_OPERATOR_EQUAL,
+		switch cfg.FilterPredicate {
+		case "greaterThan":
+			searchRequest.Filters = &weaviategrpc.Filters{
+				TestValue: &weaviategrpc.Filters_ValueNumber{ValueNumber: float64(filter)},
+				On:        []string{"category"},
+				Operator:  weaviategrpc.Filters_OPERATOR_GREATER_THAN,
+			}
+		case "lessThan":
+			searchRequest.Filters = &weaviategrpc.Filters{
+				TestValue: &weaviategrpc.Filters_ValueNumber{ValueNumber: float64(filter)},
+				On:        []string{"category"},
+				Operator:  weaviategrpc.Filters_OPERATOR_LESS_THAN,
+			}
+		default:
+			searchRequest.Filters = &weaviategrpc.Filters{
+				TestValue: &weaviategrpc.Filters_ValueText{ValueText: strconv.Itoa(filter)},
+				On:        []string{"category"},
+				Operator:  weaviategrpc.Filters_OPERATOR_EQUAL,
+			}
 		}
-
 	}
 
 	data, err := proto.Marshal(searchRequest)
@@ -217,14 +318,19 @@ func nearVectorQueryGrpc(cfg *Config, vec []float32, tenant string, filter int)
 
 func benchmarkNearVector(cfg Config) Results {
 	return benchmark(cfg, func(className string) QueryWithNeighbors {
+		vector := randomVector(cfg.Dimensions)
+		if cfg.QueryModel != nil {
+			vector = realisticRandomVector(cfg.QueryModel, cfg.QueryNoiseStddev, cfg.NormalizeQueries)
+		}
+
 		if cfg.API == "graphql" {
 			return QueryWithNeighbors{
-				Query: nearVectorQueryJSONGraphQL(cfg.ClassName, randomVector(cfg.Dimensions), cfg.Limit, cfg.WhereFilter),
+				Query: nearVectorQueryJSONGraphQL(cfg.ClassName, vector, cfg.Limit, cfg.WhereFilter),
 			}
 		}
 		if cfg.API == "grpc" {
 			return QueryWithNeighbors{
-				Query: nearVectorQueryGrpc(&cfg, randomVector(cfg.Dimensions), cfg.Tenant, -1),
+				Query: nearVectorQueryGrpc(&cfg, vector, cfg.Tenant, -1),
 			}
 		}
 
@@ -233,39 +339,7 @@ func benchmarkNearVector(cfg Config) Results {
 }
 
 func benchmarkNearVectorDuration(cfg Config) Results {
-
-	var samples sampledResults
-
-	startTime := time.Now()
-
-	var results Results
-	iterations := 0
-	for time.Since(startTime) < time.Duration(cfg.QueryDuration)*time.Second {
-		results = benchmarkNearVector(cfg)
-		samples.Min = append(samples.Min, results.Min)
-		samples.Max = append(samples.Max, results.Max)
-		samples.Mean = append(samples.Mean, results.Mean)
-		samples.Took = append(samples.Took, results.Took)
-		samples.QueriesPerSecond = append(samples.QueriesPerSecond, results.QueriesPerSecond)
-		samples.Results = append(samples.Results, results)
-		iterations += 1
-	}
-
-	var medianResult Results
-
-	medianResult.Min = time.Duration(median(samples.Min))
-	medianResult.Max = time.Duration(median(samples.Max))
-	medianResult.Mean = time.Duration(median(samples.Mean))
-	medianResult.Took = time.Duration(median(samples.Took))
-	medianResult.QueriesPerSecond = median(samples.QueriesPerSecond)
-	medianResult.Percentiles = results.Percentiles
-	medianResult.PercentilesLabels = results.PercentilesLabels
-	medianResult.Total = results.Total
-	medianResult.Successful = results.Successful
-	medianResult.Failed = results.Failed
-	medianResult.Parallelization = cfg.Parallel
-
-	log.WithFields(log.Fields{"iterations": iterations}).Infof("Queried for %d seconds", cfg.QueryDuration)
-
-	return medianResult
+	return benchmarkDuration(cfg, func(iteration int) Results {
+		return benchmarkNearVector(cfg)
+	})
 }
@@ -2,17 +2,21 @@ package cmd
 
 import (
 	"bufio"
+	"fmt"
 	"io"
 	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
+	wv1 "github.com/weaviate/weaviate/grpc/generated/protocol/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 var rawCmd = &cobra.Command{
 	Use:   "raw",
-	Short: "Benchmark raw GraphQL queries",
-	Long:  `Specify an existing dataset as a list of GraphQL queries`,
+	Short: "Benchmark raw GraphQL or gRPC queries",
+	Long:  `Specify an existing dataset as a list of GraphQL queries (one per line), or with -a grpc, a JSONL file of protojson-encoded SearchRequest bodies (one per line). With --queryDuration, the queries file is cycled (optionally reshuffled on each lap via --queryShuffle) for the given duration instead of being run once.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		cfg := globalConfig
 		cfg.Mode = "dataset"
@@ -21,9 +25,19 @@ var rawCmd = &cobra.Command{
 			fatal(err)
 		}
 
-		q, err := parseQueriesFromFile(cfg)
-		if err != nil {
-			fatal(err)
+		var result Results
+		if cfg.API == "grpc" {
+			queries, err := parseGrpcQueriesFromFile(cfg)
+			if err != nil {
+				fatal(err)
+			}
+			result = benchmarkRawGrpc(cfg, queries)
+		} else {
+			q, err := parseQueriesFromFile(cfg)
+			if err != nil {
+				fatal(err)
+			}
+			result = benchmarkRaw(cfg, q)
 		}
 
 		var w io.Writer
@@ -40,7 +54,6 @@ var rawCmd = &cobra.Command{
 
 		}
 
-		result := benchmarkRaw(cfg, q)
 		if cfg.OutputFormat == "json" {
 			result.WriteJSONTo(w)
 		} else if cfg.OutputFormat == "text" {
@@ -61,12 +74,40 @@ func initRaw() {
 		"parallel", "p", 8, "Set the number of parallel threads which send queries")
 	rawCmd.PersistentFlags().StringVarP(&globalConfig.API,
 		"api", "a", "graphql", "The API to use on benchmarks")
+	rawCmd.PersistentFlags().IntVar(&globalConfig.QueryDuration,
+		"queryDuration", 0, "Instead of running the queries file once, cycle through it for the specified duration in seconds, reporting the median across iterations (default 0, disabled)")
+	rawCmd.PersistentFlags().BoolVar(&globalConfig.QueryShuffle,
+		"queryShuffle", true, "Reshuffle the queries file each time --queryDuration cycles past the end of it, so a long soak run doesn't replay the exact same sequence every lap")
 	rawCmd.PersistentFlags().StringVarP(&globalConfig.Origin,
 		"origin", "u", "http://localhost:8080", "The origin that Weaviate is running at")
 	rawCmd.PersistentFlags().StringVarP(&globalConfig.OutputFormat,
 		"format", "f", "text", "Output format, one of [text, json]")
 	rawCmd.PersistentFlags().StringVarP(&globalConfig.OutputFile,
 		"output", "o", "", "Filename for an output file. If none provided, output to stdout only")
+	rawCmd.PersistentFlags().StringVar(&globalConfig.GrpcCAFile,
+		"grpcCAFile", "", "PEM-encoded CA bundle to verify the gRPC server certificate against when --httpScheme=https (default: the system CA pool)")
+	rawCmd.PersistentFlags().StringVar(&globalConfig.GrpcClientCertFile,
+		"grpcClientCertFile", "", "PEM-encoded client certificate for mTLS, requires --grpcClientKeyFile (default disabled)")
+	rawCmd.PersistentFlags().StringVar(&globalConfig.GrpcClientKeyFile,
+		"grpcClientKeyFile", "", "PEM-encoded client private key for mTLS, requires --grpcClientCertFile (default disabled)")
+	rawCmd.PersistentFlags().BoolVar(&globalConfig.GrpcTLSSkipVerify,
+		"grpcTLSSkipVerify", false, "Skip verifying the gRPC server certificate when --httpScheme=https; mutually exclusive with --grpcCAFile, which it would otherwise silently ignore")
+	rawCmd.PersistentFlags().StringVar(&globalConfig.GrpcAuthToken,
+		"grpcAuthToken", "", "Bearer token sent as per-RPC credentials on the gRPC query connection, for clusters that enforce auth on their gRPC port (default disabled)")
+	rawCmd.PersistentFlags().IntVar(&globalConfig.GrpcKeepaliveTimeSeconds,
+		"grpcKeepaliveTime", 0, "Send a gRPC keepalive ping after this many seconds of inactivity, so long benchmarks don't lose an idle connection to a proxy or load balancer (default disabled)")
+	rawCmd.PersistentFlags().IntVar(&globalConfig.GrpcKeepaliveTimeout,
+		"grpcKeepaliveTimeout", 20, "Seconds to wait for a keepalive ping ack before considering the connection dead (only applies when --grpcKeepaliveTime is set)")
+	rawCmd.PersistentFlags().BoolVar(&globalConfig.GrpcKeepaliveNoStream,
+		"grpcKeepalivePermitWithoutStream", false, "Send keepalive pings even when there are no in-flight RPCs (only applies when --grpcKeepaliveTime is set)")
+	rawCmd.PersistentFlags().IntVar(&globalConfig.GrpcRetryMaxAttempts,
+		"grpcRetryMaxAttempts", 0, "Max gRPC-level retry attempts for transient errors (default disabled; this command has no --max-retries flag, so query RPCs are not otherwise retried)")
+	rawCmd.PersistentFlags().IntVar(&globalConfig.GrpcRetryPerTryTimeoutSec,
+		"grpcRetryPerTryTimeout", 0, "Per-attempt timeout in seconds for --grpcRetryMaxAttempts (default: no per-attempt timeout beyond the RPC's own context deadline)")
+	rawCmd.PersistentFlags().IntVar(&globalConfig.GrpcRetryBackoffBaseMs,
+		"grpcRetryBackoffBaseMs", 100, "Base exponential backoff in milliseconds between gRPC-level retry attempts")
+	rawCmd.PersistentFlags().IntVar(&globalConfig.GrpcRetryBackoffMaxMs,
+		"grpcRetryBackoffMaxMs", 0, "Cap on the exponential backoff between gRPC-level retry attempts (default disabled: backoff grows unbounded with attempt count)")
 }
 
 func parseQueriesFromFile(cfg Config) ([]string, error) {
@@ -90,9 +131,69 @@ func parseQueriesFromFile(cfg Config) ([]string, error) {
 func benchmarkRaw(cfg Config, queries []string) Results {
 	cfg.Queries = len(queries)
 
-	i := 0
-	return benchmark(cfg, func(className string) []byte {
-		defer func() { i++ }()
-		return nearVectorQueryJSONGraphQLRaw(queries[i])
-	})
+	next := cyclingIndexFn(len(queries), cfg.QueryShuffle)
+	getQuery := func(className string) []byte {
+		return nearVectorQueryJSONGraphQLRaw(queries[next()])
+	}
+
+	if cfg.QueryDuration > 0 {
+		return benchmarkDuration(cfg, func(iteration int) Results {
+			return benchmark(cfg, getQuery)
+		})
+	}
+
+	return benchmark(cfg, getQuery)
+}
+
+// parseGrpcQueriesFromFile reads -a grpc's companion input format for the
+// raw command: one protojson-encoded weaviategrpc.SearchRequest per line,
+// matching the wire format the Weaviate grpc API itself accepts, so a
+// capture from a real client can be dropped in directly. Each line is
+// immediately proto.Marshal'd back to the binary form processQueueGrpc
+// expects in QueryWithNeighbors.Query, the same convention nearVectorQueryGrpc
+// already uses for the other commands' gRPC path.
+func parseGrpcQueriesFromFile(cfg Config) ([][]byte, error) {
+	f, err := os.Open(cfg.QueriesFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var queries [][]byte
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		req := &wv1.SearchRequest{}
+		if err := protojson.Unmarshal([]byte(line), req); err != nil {
+			return nil, fmt.Errorf("error parsing grpc raw query as SearchRequest JSON: %w", err)
+		}
+
+		data, err := proto.Marshal(req)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling SearchRequest: %w", err)
+		}
+		queries = append(queries, data)
+	}
+	return queries, scanner.Err()
+}
+
+func benchmarkRawGrpc(cfg Config, queries [][]byte) Results {
+	cfg.Queries = len(queries)
+
+	next := cyclingIndexFn(len(queries), cfg.QueryShuffle)
+	getQuery := func(className string) QueryWithNeighbors {
+		return QueryWithNeighbors{Query: queries[next()]}
+	}
+
+	if cfg.QueryDuration > 0 {
+		return benchmarkDuration(cfg, func(iteration int) Results {
+			return benchmark(cfg, getQuery)
+		})
+	}
+
+	return benchmark(cfg, getQuery)
 }
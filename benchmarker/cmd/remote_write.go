@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	log "github.com/sirupsen/logrus"
+)
+
+// RemoteWriteConfig holds configuration for pushing benchmark results to a
+// Prometheus remote_write-compatible TSDB (Prometheus, Cortex, Thanos,
+// VictoriaMetrics, Mimir, ...), so results can land in the same store a
+// user's production Weaviate metrics already live in.
+type RemoteWriteConfig struct {
+	URL         string
+	Username    string
+	Password    string
+	BearerToken string
+	Headers     map[string]string
+}
+
+// PushMetricsToRemoteWrite pushes the benchmark results to a Prometheus
+// remote_write endpoint, one prompb.TimeSeries per numeric field, mirroring
+// the fields PushMetricsToInfluxDB pushes to InfluxDB.
+func PushMetricsToRemoteWrite(cfg *Config, benchResult *ResultsJSONBenchmark) error {
+	if cfg.RemoteWriteConfig.URL == "" {
+		return nil
+	}
+
+	branch := cfg.LabelMap["branch"]
+	if branch == "" {
+		branch = "main"
+	}
+
+	baseLabels := []prompb.Label{
+		{Name: "dataset", Value: benchResult.Dataset},
+		{Name: "api", Value: benchResult.Api},
+		{Name: "run_id", Value: benchResult.RunID},
+		{Name: "branch", Value: branch},
+		{Name: "ef", Value: fmt.Sprintf("%d", benchResult.Ef)},
+	}
+
+	now := time.Now().UnixMilli()
+	metric := func(name string, value float64) prompb.TimeSeries {
+		labels := make([]prompb.Label, 0, len(baseLabels)+1)
+		labels = append(labels, prompb.Label{Name: "__name__", Value: "weaviate_benchmark_" + name})
+		labels = append(labels, baseLabels...)
+		return prompb.TimeSeries{
+			Labels:  labels,
+			Samples: []prompb.Sample{{Value: value, Timestamp: now}},
+		}
+	}
+
+	writeReq := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			metric("mean_latency_seconds", benchResult.Mean),
+			metric("p99_latency_seconds", benchResult.P99Latency),
+			metric("queries_per_second", benchResult.QueriesPerSecond),
+			metric("recall", benchResult.Recall),
+			metric("import_time_seconds", benchResult.ImportTime),
+			metric("heap_alloc_bytes", benchResult.HeapAllocBytes),
+			metric("heap_inuse_bytes", benchResult.HeapInuseBytes),
+			metric("heap_sys_bytes", benchResult.HeapSysBytes),
+			metric("ef_construction", float64(benchResult.EfConstruction)),
+			metric("max_connections", float64(benchResult.MaxConnections)),
+			metric("shards", float64(benchResult.Shards)),
+			metric("parallelization", float64(benchResult.Parallelization)),
+			metric("limit", float64(benchResult.Limit)),
+		},
+	}
+
+	data, err := proto.Marshal(writeReq)
+	if err != nil {
+		return fmt.Errorf("error marshaling remote_write request: %w", err)
+	}
+	compressed := snappy.Encode(nil, data)
+
+	req, err := http.NewRequest(http.MethodPost, cfg.RemoteWriteConfig.URL, bytes.NewReader(compressed))
+	if err != nil {
+		return fmt.Errorf("error building remote_write request: %w", err)
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	if cfg.RemoteWriteConfig.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.RemoteWriteConfig.BearerToken)
+	} else if cfg.RemoteWriteConfig.Username != "" {
+		req.SetBasicAuth(cfg.RemoteWriteConfig.Username, cfg.RemoteWriteConfig.Password)
+	}
+	for key, value := range cfg.RemoteWriteConfig.Headers {
+		req.Header.Set(key, value)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error pushing to remote_write endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("remote_write endpoint returned status %d", resp.StatusCode)
+	}
+
+	log.WithFields(log.Fields{
+		"url":     cfg.RemoteWriteConfig.URL,
+		"run_id":  benchResult.RunID,
+		"dataset": benchResult.Dataset,
+	}).Info("Successfully pushed metrics to remote_write endpoint")
+
+	return nil
+}
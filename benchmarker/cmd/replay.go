@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func initReplay() {
+	rootCmd.AddCommand(replayCmd)
+	numCPU := runtime.NumCPU()
+	replayCmd.PersistentFlags().StringVar(&globalConfig.ReplayTraceFile,
+		"trace-file", "", "Path to a JSONL trace of captured queries (timestamp_ns, tenant, query_bytes, neighbors, session_id) to replay")
+	replayCmd.PersistentFlags().StringVar(&globalConfig.ReplayMode,
+		"replay-mode", "as_fast_as_possible", "Replay scheduling mode, one of [as_fast_as_possible, real_time, scaled]")
+	replayCmd.PersistentFlags().Float64Var(&globalConfig.SpeedFactor,
+		"speed-factor", 1.0, "Multiplier applied to inter-arrival gaps for --replay-mode=scaled; >1 replays faster than the original trace")
+	replayCmd.PersistentFlags().IntVarP(&globalConfig.Parallel,
+		"parallel", "p", numCPU, "Set the number of parallel workers replaying the trace")
+	replayCmd.PersistentFlags().StringVarP(&globalConfig.API,
+		"api", "a", "grpc", "API (grpc only is supported for replay)")
+	replayCmd.PersistentFlags().IntVarP(&globalConfig.Limit,
+		"limit", "l", 10, "Set the query limit (top_k)")
+	replayCmd.PersistentFlags().StringVarP(&globalConfig.ClassName,
+		"className", "c", "", "The Weaviate class to run the benchmark against")
+	replayCmd.PersistentFlags().StringVarP(&globalConfig.Origin,
+		"grpcOrigin", "u", "localhost:50051", "The gRPC origin that Weaviate is running at")
+	replayCmd.PersistentFlags().StringVar(&globalConfig.HttpScheme,
+		"httpScheme", "http", "The http scheme (http or https)")
+	replayCmd.PersistentFlags().StringVar(&globalConfig.GrpcCAFile,
+		"grpcCAFile", "", "PEM-encoded CA bundle to verify the gRPC server certificate against when --httpScheme=https (default: the system CA pool)")
+	replayCmd.PersistentFlags().StringVar(&globalConfig.GrpcClientCertFile,
+		"grpcClientCertFile", "", "PEM-encoded client certificate for mTLS, requires --grpcClientKeyFile (default disabled)")
+	replayCmd.PersistentFlags().StringVar(&globalConfig.GrpcClientKeyFile,
+		"grpcClientKeyFile", "", "PEM-encoded client private key for mTLS, requires --grpcClientCertFile (default disabled)")
+	replayCmd.PersistentFlags().BoolVar(&globalConfig.GrpcTLSSkipVerify,
+		"grpcTLSSkipVerify", false, "Skip verifying the gRPC server certificate when --httpScheme=https; mutually exclusive with --grpcCAFile, which it would otherwise silently ignore")
+	replayCmd.PersistentFlags().StringVar(&globalConfig.GrpcAuthToken,
+		"grpcAuthToken", "", "Bearer token sent as per-RPC credentials on the gRPC connection, for clusters that enforce auth on their gRPC port (default disabled)")
+	replayCmd.PersistentFlags().StringVar(&globalConfig.GrpcCompression,
+		"grpcCompression", "none", "gRPC client-side compression, one of [none, gzip]")
+	replayCmd.PersistentFlags().IntVar(&globalConfig.GrpcKeepaliveTimeSeconds,
+		"grpcKeepaliveTime", 0, "Send a gRPC keepalive ping after this many seconds of inactivity, so long replays don't lose an idle connection to a proxy or load balancer (default disabled)")
+	replayCmd.PersistentFlags().IntVar(&globalConfig.GrpcKeepaliveTimeout,
+		"grpcKeepaliveTimeout", 20, "Seconds to wait for a keepalive ping ack before considering the connection dead (only applies when --grpcKeepaliveTime is set)")
+	replayCmd.PersistentFlags().BoolVar(&globalConfig.GrpcKeepaliveNoStream,
+		"grpcKeepalivePermitWithoutStream", false, "Send keepalive pings even when there are no in-flight RPCs (only applies when --grpcKeepaliveTime is set)")
+	replayCmd.PersistentFlags().IntVar(&globalConfig.GrpcRetryMaxAttempts,
+		"grpcRetryMaxAttempts", 0, "Max gRPC-level retry attempts for transient errors on this connection (default disabled; replay does not otherwise retry query RPCs)")
+	replayCmd.PersistentFlags().IntVar(&globalConfig.GrpcRetryPerTryTimeoutSec,
+		"grpcRetryPerTryTimeout", 0, "Per-attempt timeout in seconds for --grpcRetryMaxAttempts (default: no per-attempt timeout beyond the RPC's own context deadline)")
+	replayCmd.PersistentFlags().IntVar(&globalConfig.GrpcRetryBackoffBaseMs,
+		"grpcRetryBackoffBaseMs", 100, "Base exponential backoff in milliseconds between gRPC-level retry attempts")
+	replayCmd.PersistentFlags().IntVar(&globalConfig.GrpcRetryBackoffMaxMs,
+		"grpcRetryBackoffMaxMs", 0, "Cap on the exponential backoff between gRPC-level retry attempts (default disabled: backoff grows unbounded with attempt count)")
+}
+
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Replay a captured query trace, preserving arrival pattern and session affinity",
+	Long:  `Replay a captured query trace, preserving arrival pattern and session affinity`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := globalConfig
+		cfg.Mode = "replay"
+
+		if err := cfg.Validate(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		replay, err := LoadWorkloadReplay(cfg.ReplayTraceFile)
+		if err != nil {
+			log.Fatalf("Error loading trace file: %v", err)
+		}
+
+		log.WithFields(log.Fields{"records": len(replay.Records),
+			"mode": cfg.ReplayMode, "class": cfg.ClassName}).Info("Beginning replay benchmark")
+
+		result := benchmarkReplay(cfg, replay)
+
+		log.WithFields(log.Fields{"mean": result.Mean, "qps": result.QueriesPerSecond,
+			"parallel": cfg.Parallel, "limit": cfg.Limit,
+			"api": cfg.API, "count": result.Total, "failed": result.Failed}).Info("Benchmark result")
+	},
+}
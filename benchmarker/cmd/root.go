@@ -33,6 +33,14 @@ func init() {
 	initDataset()
 	initRaw()
 	initAnnBenchmark()
+	initCompare()
+	initReplay()
+	initLint()
+
+	rootCmd.PersistentFlags().BoolVar(&globalConfig.Silent, "silent", false,
+		"Suppress all non-essential output, including the progress bar (useful for CI logs)")
+	rootCmd.PersistentFlags().BoolVar(&globalConfig.NoProgress, "no-progress", false,
+		"Suppress the progress bar without silencing other output")
 }
 
 var rootCmd = &cobra.Command{
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+	log "github.com/sirupsen/logrus"
+)
+
+// serverMetricQueries maps the series key each sample is filed under to the
+// PromQL expression WeaviateMetricsClient runs against it when
+// cfg.PrometheusQueryURL points at a real Prometheus server.
+var serverMetricQueries = map[string]string{
+	"hnsw_build_queue_length": "sum(vector_index_queue_insert_count)",
+	"vector_index_tombstones": "sum(vector_index_tombstones)",
+	"object_count":            "sum(object_count)",
+	"lsm_segment_count":       "sum(lsm_active_segments)",
+}
+
+// serverMetricFamilies maps the same series keys to the raw metric family
+// name to look up when falling back to scraping Weaviate's own "/metrics"
+// endpoint directly, mirroring readMemoryMetrics/readHFreshMetrics - there's
+// no query language there, just the latest value of each family.
+var serverMetricFamilies = map[string]string{
+	"hnsw_build_queue_length": "vector_index_queue_insert_count",
+	"vector_index_tombstones": "vector_index_tombstones",
+	"object_count":            "object_count",
+	"lsm_segment_count":       "lsm_active_segments",
+}
+
+// MetricSample is one timestamped observation of a server-side metric.
+type MetricSample struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// ServerMetricsCollector periodically samples a fixed set of server-side
+// Weaviate metrics (HNSW insert queue depth, tombstones, object count, LSM
+// segment count) for the duration of a benchmark run, so the JSON output
+// carries server-side state for the same window client-side latency was
+// measured over. It prefers querying a real Prometheus server via
+// WeaviateMetricsClient when cfg.PrometheusQueryURL is set, and otherwise
+// falls back to scraping Weaviate's own "/metrics" endpoint directly.
+type ServerMetricsCollector struct {
+	cfg      *Config
+	client   *WeaviateMetricsClient
+	interval time.Duration
+
+	mu     sync.Mutex
+	series map[string][]MetricSample
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewServerMetricsCollector builds a collector sampling every interval (5s
+// if interval <= 0). A failure to build the Prometheus query API client is
+// logged and the collector falls back to direct "/metrics" scraping rather
+// than failing the run.
+func NewServerMetricsCollector(cfg *Config, interval time.Duration) *ServerMetricsCollector {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	var client *WeaviateMetricsClient
+	if cfg.PrometheusQueryURL != "" {
+		var err error
+		client, err = NewWeaviateMetricsClient(cfg.PrometheusQueryURL)
+		if err != nil {
+			log.WithError(err).Warn("Failed to build Prometheus query API client, falling back to direct /metrics scraping")
+		}
+	}
+
+	return &ServerMetricsCollector{
+		cfg:      cfg,
+		client:   client,
+		interval: interval,
+		series:   make(map[string][]MetricSample),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start begins sampling in the background until Stop is called.
+func (c *ServerMetricsCollector) Start() {
+	if c == nil {
+		return
+	}
+
+	go func() {
+		defer close(c.done)
+
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		c.sample()
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				c.sample()
+			}
+		}
+	}()
+}
+
+// Stop halts sampling and waits for the background goroutine to exit.
+func (c *ServerMetricsCollector) Stop() {
+	if c == nil {
+		return
+	}
+	close(c.stop)
+	<-c.done
+}
+
+// Series returns a copy of the samples collected so far, keyed the same way
+// as serverMetricQueries/serverMetricFamilies. Safe to call on a nil
+// receiver (returns nil), so benchmark() can pass it straight to analyze
+// whether or not server metrics collection is enabled.
+func (c *ServerMetricsCollector) Series() map[string][]MetricSample {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make(map[string][]MetricSample, len(c.series))
+	for key, samples := range c.series {
+		cp := make([]MetricSample, len(samples))
+		copy(cp, samples)
+		out[key] = cp
+	}
+	return out
+}
+
+func (c *ServerMetricsCollector) sample() {
+	now := time.Now()
+
+	for key := range serverMetricQueries {
+		value, err := c.sampleOne(key)
+		if err != nil {
+			log.WithError(err).WithField("metric", key).Debug("Failed to sample server metric")
+			continue
+		}
+
+		c.mu.Lock()
+		c.series[key] = append(c.series[key], MetricSample{Timestamp: now, Value: value})
+		c.mu.Unlock()
+	}
+}
+
+func (c *ServerMetricsCollector) sampleOne(key string) (float64, error) {
+	if c.client != nil {
+		return c.client.query(context.Background(), serverMetricQueries[key])
+	}
+	return c.scrapeFamily(serverMetricFamilies[key])
+}
+
+// scrapeFamily reads Weaviate's own "/metrics" endpoint directly (no PromQL,
+// just the latest gauge value), the same fallback readMemoryMetrics uses.
+func (c *ServerMetricsCollector) scrapeFamily(family string) (float64, error) {
+	prometheusURL := fmt.Sprintf("http://%s/metrics", strings.Replace(c.cfg.HttpOrigin, "8080", "2112", -1))
+
+	response, err := http.Get(prometheusURL)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("HTTP request failed with status code %d", response.StatusCode)
+	}
+
+	parser := expfmt.TextParser{}
+	metrics, err := parser.TextToMetricFamilies(response.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	metric, ok := metrics[family]
+	if !ok || len(metric.Metric) == 0 {
+		return 0, nil
+	}
+
+	var sum float64
+	for _, m := range metric.Metric {
+		sum += m.GetGauge().GetValue()
+	}
+	return sum, nil
+}
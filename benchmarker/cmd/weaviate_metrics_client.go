@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// WeaviateMetricsClient queries a Prometheus server (typically the one
+// scraping Weaviate itself) via its HTTP API, so a benchmark run can pull
+// server-side series - CPU, memory, compaction lag, HNSW insert queue depth
+// - for the same window client-side latency was measured over, rather than
+// the one-off text scrapes of a single instant readMemoryMetrics and
+// readHFreshMetrics perform against Weaviate's own "/metrics" endpoint.
+type WeaviateMetricsClient struct {
+	api v1.API
+}
+
+// NewWeaviateMetricsClient builds a client against queryURL, the address of
+// a Prometheus server - not Weaviate's own "/metrics" endpoint, which has no
+// query language of its own, only the latest value of each series.
+func NewWeaviateMetricsClient(queryURL string) (*WeaviateMetricsClient, error) {
+	client, err := api.NewClient(api.Config{Address: queryURL})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prometheus api client: %w", err)
+	}
+
+	return &WeaviateMetricsClient{api: v1.NewAPI(client)}, nil
+}
+
+// QueryRange runs expr over [start, end] at the given step and returns the
+// resulting range vector.
+func (c *WeaviateMetricsClient) QueryRange(ctx context.Context, expr string, start, end time.Time, step time.Duration) (model.Matrix, error) {
+	value, warnings, err := c.api.QueryRange(ctx, expr, v1.Range{Start: start, End: end, Step: step})
+	if err != nil {
+		return nil, fmt.Errorf("prometheus range query %q: %w", expr, err)
+	}
+	logPrometheusWarnings(expr, warnings)
+
+	matrix, ok := value.(model.Matrix)
+	if !ok {
+		return nil, fmt.Errorf("unexpected prometheus result type %T for range query %q", value, expr)
+	}
+	return matrix, nil
+}
+
+// query runs an instant query and returns the value of its first sample, or
+// 0 if the query returned no samples.
+func (c *WeaviateMetricsClient) query(ctx context.Context, expr string) (float64, error) {
+	value, warnings, err := c.api.Query(ctx, expr, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("prometheus query %q: %w", expr, err)
+	}
+	logPrometheusWarnings(expr, warnings)
+
+	vector, ok := value.(model.Vector)
+	if !ok || len(vector) == 0 {
+		return 0, nil
+	}
+	return float64(vector[0].Value), nil
+}
+
+func logPrometheusWarnings(expr string, warnings v1.Warnings) {
+	for _, w := range warnings {
+		log.WithFields(log.Fields{"query": expr, "warning": w}).Warn("Prometheus query returned a warning")
+	}
+}
+
+// HNSWBuildQueueLength returns the number of vectors queued for HNSW
+// insertion across all shards.
+func (c *WeaviateMetricsClient) HNSWBuildQueueLength(ctx context.Context) (float64, error) {
+	return c.query(ctx, serverMetricQueries["hnsw_build_queue_length"])
+}
+
+// VectorIndexTombstones returns the number of pending tombstones across all
+// vector indexes - the same family waitTombstonesEmpty polls, but read via
+// the Prometheus query API instead of scraping Weaviate's "/metrics" text
+// directly.
+func (c *WeaviateMetricsClient) VectorIndexTombstones(ctx context.Context) (float64, error) {
+	return c.query(ctx, serverMetricQueries["vector_index_tombstones"])
+}
+
+// ObjectCount returns the total number of objects stored.
+func (c *WeaviateMetricsClient) ObjectCount(ctx context.Context) (float64, error) {
+	return c.query(ctx, serverMetricQueries["object_count"])
+}
+
+// LSMSegmentCount returns the number of active LSM segments across all
+// stores, a proxy for compaction lag.
+func (c *WeaviateMetricsClient) LSMSegmentCount(ctx context.Context) (float64, error) {
+	return c.query(ctx, serverMetricQueries["lsm_segment_count"])
+}
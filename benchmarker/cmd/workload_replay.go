@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	wv1 "github.com/weaviate/weaviate/grpc/generated/protocol/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// ReplayRecord is one line of a --trace-file: a captured query plus the
+// wall-clock offset (relative to the start of the trace) it was originally
+// issued at. Neighbors is optional - traces captured from production
+// traffic rarely carry nearest-neighbor ground truth, in which case recall
+// and NDCG are simply skipped for that query. SessionID, when set, pins
+// every record sharing it to the same worker so per-user think time
+// (multiple queries in a row from one session) is preserved instead of
+// being smeared across workers.
+type ReplayRecord struct {
+	TimestampNs int64  `json:"timestamp_ns"`
+	Tenant      string `json:"tenant"`
+	Query       []byte `json:"query_bytes"`
+	Neighbors   []int  `json:"neighbors,omitempty"`
+	SessionID   string `json:"session_id,omitempty"`
+}
+
+// WorkloadReplay is a parsed --trace-file: the captured queries in the
+// order they were recorded in.
+type WorkloadReplay struct {
+	Records []ReplayRecord
+}
+
+// LoadWorkloadReplay reads a JSONL trace file, one ReplayRecord per line.
+func LoadWorkloadReplay(path string) (*WorkloadReplay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening trace file: %w", err)
+	}
+	defer f.Close()
+
+	var records []ReplayRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var record ReplayRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("error parsing trace record: %w", err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading trace file: %w", err)
+	}
+
+	return &WorkloadReplay{Records: records}, nil
+}
+
+// TenantResults is a per-tenant slice of the run-wide Results, so a
+// regression confined to one tenant or query shape is visible instead of
+// being averaged away in the aggregate numbers.
+type TenantResults struct {
+	Total      int
+	Successful int
+	Mean       time.Duration
+	Recall     float64
+	NDCG       float64
+}
+
+// tenantAccumulator is the mutable, mutex-guarded form of TenantResults
+// built up while a replay is running; it's reduced to TenantResults once
+// the run completes.
+type tenantAccumulator struct {
+	times  []time.Duration
+	recall []float64
+	ndcg   []float64
+}
+
+// benchmarkReplay is the replay-driven counterpart to benchmark: instead of
+// a getQueryFn that synthesizes queries on demand, it reproduces the
+// arrival pattern of a captured WorkloadReplay. Like benchmarkOpenLoop, it's
+// a self-contained implementation with its own grpc dial setup rather than
+// a retrofit of processQueueGrpc, since a schedule driven by recorded
+// timestamps and pinned session affinity doesn't fit the pull-based
+// getQueryFn model the rest of benchmark() is built around.
+func benchmarkReplay(cfg Config, replay *WorkloadReplay) Results {
+	cfg.Queries = len(replay.Records)
+
+	var times []time.Duration
+	var recall []float64
+	var ndcg []float64
+	var timedOut int
+	m := &sync.Mutex{}
+
+	tenants := make(map[string]*tenantAccumulator)
+	tenantFor := func(tenant string) *tenantAccumulator {
+		acc, ok := tenants[tenant]
+		if !ok {
+			acc = &tenantAccumulator{}
+			tenants[tenant] = acc
+		}
+		return acc
+	}
+
+	queryTimeout := time.Duration(cfg.QueryTimeoutSeconds) * time.Second
+	if queryTimeout <= 0 {
+		queryTimeout = 30 * time.Second
+	}
+
+	runCtx := context.Background()
+	if cfg.RunDeadlineSeconds > 0 {
+		var runCancel context.CancelFunc
+		runCtx, runCancel = context.WithTimeout(runCtx, time.Duration(cfg.RunDeadlineSeconds)*time.Second)
+		defer runCancel()
+	}
+
+	httpOption := buildGrpcTransportOption(&cfg)
+
+	grpcCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	dialOptions := append([]grpc.DialOption{httpOption}, grpcTuningDialOptions(&cfg)...)
+	if perRPC := grpcPerRPCDialOption(&cfg); perRPC != nil {
+		dialOptions = append(dialOptions, perRPC)
+	}
+	if retryOpt := grpcRetryDialOption(&cfg); retryOpt != nil {
+		dialOptions = append(dialOptions, retryOpt)
+	}
+	grpcConn, err := grpc.DialContext(grpcCtx, cfg.Origin, dialOptions...)
+	if err != nil {
+		log.Fatalf("Did not connect: %v", err)
+	}
+	defer grpcConn.Close()
+	grpcClient := wv1.NewWeaviateClient(grpcConn)
+
+	// Session affinity: every record sharing a non-empty SessionID is
+	// serialized onto the same worker, in trace order, so a session's
+	// queries never run concurrently with each other. Session-less records
+	// are simply round-robined like the closed-loop queue partitioning does.
+	queues := make([][]int, cfg.Parallel)
+	sessionWorker := make(map[string]int)
+	nextWorker := 0
+	for i, record := range replay.Records {
+		var worker int
+		if record.SessionID == "" {
+			worker = nextWorker % cfg.Parallel
+			nextWorker++
+		} else if w, ok := sessionWorker[record.SessionID]; ok {
+			worker = w
+		} else {
+			worker = nextWorker % cfg.Parallel
+			nextWorker++
+			sessionWorker[record.SessionID] = worker
+		}
+		queues[worker] = append(queues[worker], i)
+	}
+
+	speedFactor := cfg.SpeedFactor
+	if speedFactor <= 0 {
+		speedFactor = 1
+	}
+
+	wg := &sync.WaitGroup{}
+	before := time.Now()
+	for _, queue := range queues {
+		wg.Add(1)
+		go func(queue []int) {
+			defer wg.Done()
+
+			var prevTimestampNs int64
+			first := true
+
+			for _, idx := range queue {
+				if runCtx.Err() != nil {
+					return
+				}
+
+				record := replay.Records[idx]
+
+				if cfg.ReplayMode != "as_fast_as_possible" && !first {
+					gap := time.Duration(record.TimestampNs - prevTimestampNs)
+					if cfg.ReplayMode == "scaled" {
+						gap = time.Duration(float64(gap) / speedFactor)
+					}
+					if gap > 0 {
+						time.Sleep(gap)
+					}
+				}
+				first = false
+				prevTimestampNs = record.TimestampNs
+
+				searchRequest := &wv1.SearchRequest{}
+				if err := proto.Unmarshal(record.Query, searchRequest); err != nil {
+					log.Errorf("Failed to unmarshal replay query: %v", err)
+					continue
+				}
+
+				queryBefore := time.Now()
+				ctx, cancel := context.WithTimeout(runCtx, queryTimeout)
+				searchReply, err := grpcClient.Search(ctx, searchRequest)
+				ctxErr := ctx.Err()
+				cancel()
+				took := time.Since(queryBefore)
+				if err != nil {
+					if ctxErr != nil {
+						m.Lock()
+						timedOut++
+						m.Unlock()
+					} else {
+						fmt.Printf("ERROR: %v\n", err)
+					}
+					continue
+				}
+
+				ids := make([]int, 0, len(searchReply.GetResults()))
+				for _, result := range searchReply.GetResults() {
+					ids = append(ids, intFromUUID(result.GetMetadata().Id))
+				}
+
+				var recallQuery, ndcgQuery float64
+				haveNeighbors := record.Neighbors != nil
+				if haveNeighbors {
+					neighborLimit := min(cfg.Limit, len(record.Neighbors))
+					recallQuery = float64(len(intersection(ids, record.Neighbors[:neighborLimit]))) / float64(neighborLimit)
+					ndcgQuery = computeNDCG(ids, record.Neighbors[:neighborLimit], neighborLimit)
+				}
+
+				m.Lock()
+				times = append(times, took)
+				if haveNeighbors {
+					recall = append(recall, recallQuery)
+					ndcg = append(ndcg, ndcgQuery)
+				}
+				acc := tenantFor(record.Tenant)
+				acc.times = append(acc.times, took)
+				if haveNeighbors {
+					acc.recall = append(acc.recall, recallQuery)
+					acc.ndcg = append(acc.ndcg, ndcgQuery)
+				}
+				m.Unlock()
+			}
+		}(queue)
+	}
+	wg.Wait()
+
+	out := analyze(cfg, times, time.Since(before), recall, ndcg, timedOut, nil, nil, nil)
+	out.PerTenant = reduceTenantAccumulators(tenants)
+	return out
+}
+
+// reduceTenantAccumulators turns the mutex-guarded per-tenant scratch space
+// collected while a replay is running into the final, read-only
+// TenantResults reported on Results.
+func reduceTenantAccumulators(tenants map[string]*tenantAccumulator) map[string]*TenantResults {
+	if len(tenants) == 0 {
+		return nil
+	}
+
+	out := make(map[string]*TenantResults, len(tenants))
+	for tenant, acc := range tenants {
+		result := &TenantResults{Total: len(acc.times), Successful: len(acc.times)}
+
+		var sum time.Duration
+		for _, t := range acc.times {
+			sum += t
+		}
+		if len(acc.times) > 0 {
+			result.Mean = sum / time.Duration(len(acc.times))
+		}
+
+		var sumRecall float64
+		for _, r := range acc.recall {
+			sumRecall += r
+		}
+		if len(acc.recall) > 0 {
+			result.Recall = sumRecall / float64(len(acc.recall))
+		}
+
+		var sumNDCG float64
+		for _, n := range acc.ndcg {
+			sumNDCG += n
+		}
+		if len(acc.ndcg) > 0 {
+			result.NDCG = sumNDCG / float64(len(acc.ndcg))
+		}
+
+		out[tenant] = result
+	}
+	return out
+}
@@ -4,14 +4,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
 	"github.com/spf13/cobra"
 )
 
@@ -20,60 +23,138 @@ const (
 )
 
 type MetricData struct {
-	API            string  `json:"api"`
-	Branch         string  `json:"branch"`
-	DatasetFile    string  `json:"dataset_file"`
-	EF             int     `json:"ef"`
-	EFConstruction int     `json:"efConstruction"`
-	Limit          int     `json:"limit"`
-	MaxConnections int     `json:"maxConnections"`
-	MeanLatency    float64 `json:"meanLatency"`
-	P99Latency     float64 `json:"p99Latency"`
-	QPS            float64 `json:"qps"`
-	Recall         float64 `json:"recall"`
-	Shards         int     `json:"shards"`
-	ImportTime     float64 `json:"importTime"`
-	HeapAllocBytes float64 `json:"heap_alloc_bytes"`
-	HeapInuseBytes float64 `json:"heap_inuse_bytes"`
-	HeapSysBytes   float64 `json:"heap_sys_bytes"`
+	API             string          `json:"api"`
+	Branch          string          `json:"branch"`
+	DatasetFile     string          `json:"dataset_file"`
+	EF              int             `json:"ef"`
+	EFConstruction  int             `json:"efConstruction"`
+	Limit           int             `json:"limit"`
+	MaxConnections  int             `json:"maxConnections"`
+	MeanLatency     float64         `json:"meanLatency"`
+	P99Latency      float64         `json:"p99Latency"`
+	QPS             float64         `json:"qps"`
+	Recall          float64         `json:"recall"`
+	Shards          int             `json:"shards"`
+	ImportTime      float64         `json:"importTime"`
+	HeapAllocBytes  float64         `json:"heap_alloc_bytes"`
+	HeapInuseBytes  float64         `json:"heap_inuse_bytes"`
+	HeapSysBytes    float64         `json:"heap_sys_bytes"`
+	Histogram       NativeHistogram `json:"latency_histogram"`
+	QueriesTimedOut int             `json:"queries_timed_out"`
+	// Timestamp, if set (RFC3339), is when this result was produced. It
+	// drives the ring buffer's ordering and is what lets historical JSONs
+	// dropped into the watched directory be backfilled with their original
+	// time rather than "now". Defaults to the time the file is processed
+	// when absent, matching the exporter's pre-existing live-only behavior.
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+// HistogramSpan and NativeHistogram mirror the sparse bucket encoding the
+// benchmarker writes into its result files (see cmd/benchmark_run.go in the
+// benchmarker module), so a result file can be decoded here without a
+// dependency between the two modules.
+type HistogramSpan struct {
+	Offset int32  `json:"offset"`
+	Length uint32 `json:"length"`
+}
+
+type NativeHistogram struct {
+	Schema         int32           `json:"schema"`
+	ZeroThreshold  float64         `json:"zero_threshold"`
+	ZeroCount      uint64          `json:"zero_count"`
+	PositiveSpans  []HistogramSpan `json:"positive_spans"`
+	PositiveDeltas []int64         `json:"positive_deltas"`
+	Sum            float64         `json:"sum"`
+	Count          uint64          `json:"count"`
 }
 
 type Exporter struct {
-	metrics map[string]*prometheus.GaugeVec
+	collector   *ringBufferCollector
+	latencyHist *prometheus.HistogramVec
+
+	pushGatewayURL      string
+	pushGatewayJob      string
+	pushGatewayInstance string
+
+	// remoteWriteURL, when set, is where streamBackfill POSTs every
+	// historical sample the collector is holding.
+	remoteWriteURL string
+
+	mu               sync.Mutex
+	maxTimestampSeen time.Time
 }
 
 func NewExporter() *Exporter {
-	return &Exporter{
-		metrics: make(map[string]*prometheus.GaugeVec),
-	}
+	return &Exporter{}
+}
+
+var exporterMetrics = []metricDesc{
+	{"latency_mean", "Mean latency of queries"},
+	{"latency_p99", "99th percentile latency of queries"},
+	{"qps", "Queries per second"},
+	{"recall", "Recall metric"},
+	{"heap_alloc_bytes", "Heap alloc bytes"},
+	{"heap_sys_bytes", "Heap sys bytes"},
+	{"heap_inuse_bytes", "Heap inuse bytes"},
+	{"import_time", "Import time"},
+	{"queries_timed_out", "Number of queries that exceeded the configured query timeout"},
 }
 
+var exporterLabels = []string{"branch", "dataset", "ef_construction", "max_connections", "limit", "ef", "shards"}
+
 func (e *Exporter) initializeMetrics() {
-	labels := []string{"branch", "dataset", "ef_construction", "max_connections", "limit", "ef", "shards"}
-
-	metricNames := []struct {
-		name string
-		help string
-	}{
-		{"latency_mean", "Mean latency of queries"},
-		{"latency_p99", "99th percentile latency of queries"},
-		{"qps", "Queries per second"},
-		{"recall", "Recall metric"},
-		{"heap_alloc_bytes", "Heap alloc bytes"},
-		{"heap_sys_bytes", "Heap sys bytes"},
-		{"heap_inuse_bytes", "Heap inuse bytes"},
-		{"import_time", "Import time"},
+	e.collector = newRingBufferCollector(exporterLabels, exporterMetrics)
+	prometheus.MustRegister(e.collector)
+
+	// Native histogram bucket factor matches schema=3 (base = 2^(1/8)) used
+	// when the benchmarker builds its sparse histogram, so replaying its
+	// buckets below lines up with the same resolution.
+	e.latencyHist = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace:                   namespace,
+			Name:                        "query_latency_seconds",
+			Help:                        "Distribution of per-query latencies",
+			NativeHistogramBucketFactor: nativeHistogramBucketFactor,
+		},
+		exporterLabels,
+	)
+}
+
+const nativeHistogramBucketFactor = 1.0905077326652577 // 2^(1/8), i.e. schema=3
+
+// replayLatencyHistogram re-observes a benchmark run's sparse native
+// histogram into our own HistogramVec. client_golang has no public API to
+// load pre-aggregated bucket counts directly, so each bucket's count is
+// replayed as that many observations at the bucket's geometric mean -
+// close enough for the quantiles this histogram is meant to expose.
+func (e *Exporter) replayLatencyHistogram(hist NativeHistogram, labels prometheus.Labels) {
+	observer := e.latencyHist.With(labels)
+
+	if hist.ZeroCount > 0 {
+		for i := uint64(0); i < hist.ZeroCount; i++ {
+			observer.Observe(0)
+		}
 	}
 
-	for _, metric := range metricNames {
-		e.metrics[metric.name] = promauto.NewGaugeVec(
-			prometheus.GaugeOpts{
-				Namespace: namespace,
-				Name:      metric.name,
-				Help:      metric.help,
-			},
-			labels,
-		)
+	base := math.Pow(2, math.Pow(2, -float64(hist.Schema)))
+	bucketIndex := 0
+	deltaPos := 0
+	count := int64(0)
+
+	for _, span := range hist.PositiveSpans {
+		bucketIndex += int(span.Offset)
+		for i := uint32(0); i < span.Length; i++ {
+			if deltaPos < len(hist.PositiveDeltas) {
+				count += hist.PositiveDeltas[deltaPos]
+				deltaPos++
+			}
+
+			bucketMean := math.Pow(base, float64(bucketIndex)+0.5)
+			for n := int64(0); n < count; n++ {
+				observer.Observe(bucketMean)
+			}
+			bucketIndex++
+		}
 	}
 }
 
@@ -87,50 +168,84 @@ func (e *Exporter) processJSONFile(filepath string) error {
 		return fmt.Errorf("error parsing JSON from file %s: %v", filepath, err)
 	}
 
-	// Reset metrics before processing new data
-	for _, metric := range e.metrics {
-		metric.Reset()
-	}
-
-	// Update metrics with new values
+	var oldestSeen, newestSeen time.Time
 	for _, data := range metricsData {
 		if data.Branch == "" {
 			data.Branch = "main"
 		}
 
-		labels := prometheus.Labels{
-			"branch":          data.Branch,
-			"dataset":         data.DatasetFile,
-			"ef_construction": fmt.Sprintf("%d", data.EFConstruction),
-			"max_connections": fmt.Sprintf("%d", data.MaxConnections),
-			"limit":           fmt.Sprintf("%d", data.Limit),
-			"ef":              fmt.Sprintf("%d", data.EF),
-			"shards":          fmt.Sprintf("%d", data.Shards),
-		}
-
-		if metric := e.metrics["latency_mean"]; metric != nil {
-			metric.With(labels).Set(data.MeanLatency)
+		timestamp := time.Now()
+		if data.Timestamp != "" {
+			if parsed, err := time.Parse(time.RFC3339, data.Timestamp); err == nil {
+				timestamp = parsed
+			} else {
+				log.Printf("Ignoring unparseable timestamp %q in %s: %v", data.Timestamp, filepath, err)
+			}
 		}
-		if metric := e.metrics["latency_p99"]; metric != nil {
-			metric.With(labels).Set(data.P99Latency)
+		if oldestSeen.IsZero() || timestamp.Before(oldestSeen) {
+			oldestSeen = timestamp
 		}
-		if metric := e.metrics["qps"]; metric != nil {
-			metric.With(labels).Set(data.QPS)
+		if timestamp.After(newestSeen) {
+			newestSeen = timestamp
 		}
-		if metric := e.metrics["recall"]; metric != nil {
-			metric.With(labels).Set(data.Recall)
+
+		labelValues := []string{
+			data.Branch,
+			data.DatasetFile,
+			fmt.Sprintf("%d", data.EFConstruction),
+			fmt.Sprintf("%d", data.MaxConnections),
+			fmt.Sprintf("%d", data.Limit),
+			fmt.Sprintf("%d", data.EF),
+			fmt.Sprintf("%d", data.Shards),
 		}
-		if metric := e.metrics["import_time"]; metric != nil {
-			metric.With(labels).Set(data.ImportTime)
+
+		e.collector.record("latency_mean", labelValues, timestamp, data.MeanLatency)
+		e.collector.record("latency_p99", labelValues, timestamp, data.P99Latency)
+		e.collector.record("qps", labelValues, timestamp, data.QPS)
+		e.collector.record("recall", labelValues, timestamp, data.Recall)
+		e.collector.record("import_time", labelValues, timestamp, data.ImportTime)
+		e.collector.record("heap_alloc_bytes", labelValues, timestamp, data.HeapAllocBytes)
+		e.collector.record("heap_inuse_bytes", labelValues, timestamp, data.HeapInuseBytes)
+		e.collector.record("heap_sys_bytes", labelValues, timestamp, data.HeapSysBytes)
+		e.collector.record("queries_timed_out", labelValues, timestamp, float64(data.QueriesTimedOut))
+
+		labels := prometheus.Labels{
+			"branch":          data.Branch,
+			"dataset":         data.DatasetFile,
+			"ef_construction": labelValues[2],
+			"max_connections": labelValues[3],
+			"limit":           labelValues[4],
+			"ef":              labelValues[5],
+			"shards":          labelValues[6],
 		}
-		if metric := e.metrics["heap_inuse_bytes"]; metric != nil {
-			metric.With(labels).Set(data.HeapInuseBytes)
+		e.replayLatencyHistogram(data.Histogram, labels)
+
+		if e.pushGatewayURL != "" {
+			if err := e.pushToGateway(data); err != nil {
+				log.Printf("Error pushing to pushgateway: %v", err)
+			}
 		}
-		if metric := e.metrics["heap_alloc_bytes"]; metric != nil {
-			metric.With(labels).Set(data.HeapAllocBytes)
+	}
+
+	// If this file's data reaches further back than the newest timestamp seen
+	// so far, the new samples landed out of order with respect to the ring
+	// buffers' existing history - stream the whole history to remote_write
+	// again so a downstream TSDB that already scraped the old "latest" picks
+	// up the now-earlier points too.
+	if !oldestSeen.IsZero() {
+		e.mu.Lock()
+		isOlder := !e.maxTimestampSeen.IsZero() && oldestSeen.Before(e.maxTimestampSeen)
+		if newestSeen.After(e.maxTimestampSeen) {
+			e.maxTimestampSeen = newestSeen
 		}
-		if metric := e.metrics["heap_sys_bytes"]; metric != nil {
-			metric.With(labels).Set(data.HeapSysBytes)
+		e.mu.Unlock()
+
+		if isOlder && e.remoteWriteURL != "" {
+			go func() {
+				if err := e.streamBackfill(); err != nil {
+					log.Printf("Error streaming backfill after out-of-order data: %v", err)
+				}
+			}()
 		}
 	}
 
@@ -138,6 +253,43 @@ func (e *Exporter) processJSONFile(filepath string) error {
 	return nil
 }
 
+// pushToGateway forwards a single benchmark result to a Prometheus
+// Pushgateway, grouped by branch and dataset so short-lived CI runs that
+// exit before the /metrics endpoint is scraped are still recorded.
+func (e *Exporter) pushToGateway(data MetricData) error {
+	registry := prometheus.NewRegistry()
+	metrics := make(map[string]prometheus.Gauge)
+
+	add := func(name, help string, value float64) {
+		g := prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      name,
+			Help:      help,
+		})
+		g.Set(value)
+		metrics[name] = g
+		registry.MustRegister(g)
+	}
+
+	add("latency_mean", "Mean latency of queries", data.MeanLatency)
+	add("latency_p99", "99th percentile latency of queries", data.P99Latency)
+	add("qps", "Queries per second", data.QPS)
+	add("recall", "Recall metric", data.Recall)
+	add("import_time", "Import time", data.ImportTime)
+	add("heap_alloc_bytes", "Heap alloc bytes", data.HeapAllocBytes)
+	add("heap_inuse_bytes", "Heap inuse bytes", data.HeapInuseBytes)
+	add("heap_sys_bytes", "Heap sys bytes", data.HeapSysBytes)
+	add("queries_timed_out", "Number of queries that exceeded the configured query timeout", float64(data.QueriesTimedOut))
+
+	pusher := push.New(e.pushGatewayURL, e.pushGatewayJob).
+		Grouping("instance", e.pushGatewayInstance).
+		Grouping("branch", data.Branch).
+		Grouping("dataset", data.DatasetFile).
+		Gatherer(registry)
+
+	return pusher.Push()
+}
+
 func findLatestJSONFile(dirPath string) (string, error) {
 	var latestFile string
 	var latestTime time.Time
@@ -192,8 +344,12 @@ func pollDirectory(dirPath string, exporter *Exporter) {
 
 func main() {
 	var (
-		dirPath string
-		port    int
+		dirPath             string
+		port                int
+		pushGatewayURL      string
+		pushGatewayJob      string
+		pushGatewayInstance string
+		remoteWriteURL      string
 	)
 
 	// Create root command
@@ -206,12 +362,31 @@ func main() {
 			prometheus.Unregister(prometheus.NewGoCollector())
 			exporter := NewExporter()
 			exporter.initializeMetrics()
+			exporter.pushGatewayURL = pushGatewayURL
+			exporter.pushGatewayJob = pushGatewayJob
+			exporter.pushGatewayInstance = pushGatewayInstance
+			exporter.remoteWriteURL = remoteWriteURL
 
 			// Start polling directory
 			go pollDirectory(dirPath, exporter)
 
+			if exporter.remoteWriteURL != "" {
+				go func() {
+					if err := exporter.streamBackfill(); err != nil {
+						log.Printf("Error streaming initial backfill: %v", err)
+					}
+				}()
+			}
+
 			// Set up HTTP server
 			http.Handle("/metrics", promhttp.Handler())
+			http.HandleFunc("/remote_write_backfill", func(w http.ResponseWriter, r *http.Request) {
+				if err := exporter.streamBackfill(); err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				w.Write([]byte("backfill complete"))
+			})
 			http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 				w.Write([]byte(`<html>
 					<head><title>Performance Metrics Exporter</title></head>
@@ -241,6 +416,10 @@ func main() {
 	rootCmd.Flags().StringVarP(&dirPath, "dir", "d", "", "Results directory path to watch (required)")
 	rootCmd.MarkFlagRequired("dir")
 	rootCmd.Flags().IntVarP(&port, "port", "p", 2120, "Port to serve metrics on")
+	rootCmd.Flags().StringVar(&pushGatewayURL, "push-gateway", "", "Prometheus Pushgateway URL to push results to as they're processed (default disabled)")
+	rootCmd.Flags().StringVar(&pushGatewayJob, "push-job", "weaviate-benchmarker", "Job name to use when pushing to the Pushgateway")
+	rootCmd.Flags().StringVar(&pushGatewayInstance, "push-instance", "metrics-exporter", "Instance name to use when pushing to the Pushgateway")
+	rootCmd.Flags().StringVar(&remoteWriteURL, "remote-write-url", "", "Prometheus remote_write URL to stream historical samples to on startup and /remote_write_backfill (default disabled)")
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// backfillBatchSize bounds how many samples go into a single
+// prompb.WriteRequest, so a backfill of a large history doesn't build one
+// enormous request a remote_write receiver is likely to reject.
+const backfillBatchSize = 500
+
+// streamBackfill replays every historical sample the collector is holding
+// to the configured remote-write URL, in fixed-size batches, so the
+// receiving TSDB ends up with the same history /metrics itself can never
+// expose (a scrape only ever sees each series' latest value).
+func (e *Exporter) streamBackfill() error {
+	if e.remoteWriteURL == "" {
+		return fmt.Errorf("no --remote-write-url configured for backfill")
+	}
+
+	samples := e.collector.allSamples()
+	for i := 0; i < len(samples); i += backfillBatchSize {
+		end := i + backfillBatchSize
+		if end > len(samples) {
+			end = len(samples)
+		}
+
+		if err := e.pushBackfillBatch(samples[i:end]); err != nil {
+			return fmt.Errorf("error pushing backfill batch %d-%d: %w", i, end, err)
+		}
+	}
+
+	return nil
+}
+
+// pushBackfillBatch snappy-compresses a prompb.WriteRequest built from
+// batch and POSTs it to e.remoteWriteURL, following the same headers the
+// Prometheus remote_write protocol requires.
+func (e *Exporter) pushBackfillBatch(batch []backfillSample) error {
+	timeseries := make([]prompb.TimeSeries, 0, len(batch))
+	for _, smp := range batch {
+		labels := make([]prompb.Label, 0, len(e.collector.labelNames)+1)
+		labels = append(labels, prompb.Label{Name: "__name__", Value: fmt.Sprintf("%s_%s", namespace, smp.name)})
+		for i, name := range e.collector.labelNames {
+			if i < len(smp.labelValues) {
+				labels = append(labels, prompb.Label{Name: name, Value: smp.labelValues[i]})
+			}
+		}
+
+		timeseries = append(timeseries, prompb.TimeSeries{
+			Labels:  labels,
+			Samples: []prompb.Sample{{Value: smp.value, Timestamp: smp.timestamp.UnixMilli()}},
+		})
+	}
+
+	data, err := proto.Marshal(&prompb.WriteRequest{Timeseries: timeseries})
+	if err != nil {
+		return fmt.Errorf("error marshaling remote_write request: %w", err)
+	}
+	compressed := snappy.Encode(nil, data)
+
+	req, err := http.NewRequest(http.MethodPost, e.remoteWriteURL, bytes.NewReader(compressed))
+	if err != nil {
+		return fmt.Errorf("error building remote_write request: %w", err)
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error pushing to remote_write endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("remote_write endpoint returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// maxRingBufferSamples bounds how many historical samples a single
+// label-set/metric pair keeps. Benchmark artifacts are small and infrequent
+// (one drop per CI run), so this comfortably covers months of history
+// while keeping memory bounded.
+const maxRingBufferSamples = 10000
+
+// sample is one (timestamp, value) observation.
+type sample struct {
+	timestamp time.Time
+	value     float64
+}
+
+// ringBuffer is an append-only, size-bounded history of samples for one
+// label-set/metric pair, oldest-first.
+type ringBuffer struct {
+	samples []sample
+}
+
+func (r *ringBuffer) add(s sample) {
+	i := sort.Search(len(r.samples), func(i int) bool { return r.samples[i].timestamp.After(s.timestamp) })
+	r.samples = append(r.samples, sample{})
+	copy(r.samples[i+1:], r.samples[i:])
+	r.samples[i] = s
+	if len(r.samples) > maxRingBufferSamples {
+		r.samples = r.samples[len(r.samples)-maxRingBufferSamples:]
+	}
+}
+
+func (r *ringBuffer) latest() (sample, bool) {
+	if len(r.samples) == 0 {
+		return sample{}, false
+	}
+	return r.samples[len(r.samples)-1], true
+}
+
+// ringSeries is every metric's ring buffer for one label-value combination.
+type ringSeries struct {
+	labelValues []string
+	buffers     map[string]*ringBuffer // metric name -> samples
+}
+
+// backfillSample is one historical observation, denormalized enough to
+// build a prompb.TimeSeries from without holding the collector's lock.
+type backfillSample struct {
+	name        string
+	labelValues []string
+	timestamp   time.Time
+	value       float64
+}
+
+// ringBufferCollector is a custom prometheus.Collector that, unlike a
+// GaugeVec, never discards history: every recorded sample is kept (up to
+// maxRingBufferSamples per label-set/metric pair), so a backfill run can
+// still replay all of it even though Collect only ever reports the most
+// recent sample per series, matching what a GaugeVec would have reported.
+type ringBufferCollector struct {
+	mu         sync.Mutex
+	descs      map[string]*prometheus.Desc
+	labelNames []string
+	series     map[string]*ringSeries
+}
+
+type metricDesc struct {
+	name string
+	help string
+}
+
+func newRingBufferCollector(labelNames []string, metrics []metricDesc) *ringBufferCollector {
+	descs := make(map[string]*prometheus.Desc, len(metrics))
+	for _, m := range metrics {
+		descs[m.name] = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", m.name), m.help, labelNames, nil)
+	}
+
+	return &ringBufferCollector{
+		descs:      descs,
+		labelNames: labelNames,
+		series:     make(map[string]*ringSeries),
+	}
+}
+
+func (c *ringBufferCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range c.descs {
+		ch <- d
+	}
+}
+
+func (c *ringBufferCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, s := range c.series {
+		for name, buf := range s.buffers {
+			desc := c.descs[name]
+			if desc == nil {
+				continue
+			}
+
+			latest, ok := buf.latest()
+			if !ok {
+				continue
+			}
+
+			metric := prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, latest.value, s.labelValues...)
+			ch <- prometheus.NewMetricWithTimestamp(latest.timestamp, metric)
+		}
+	}
+}
+
+// seriesKey joins label values with a separator that can't appear in a
+// Prometheus label value, so distinct label-value tuples never collide.
+func seriesKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+// record appends one observation for metric name/labelValues, creating the
+// series if this is its first sample.
+func (c *ringBufferCollector) record(name string, labelValues []string, timestamp time.Time, value float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := seriesKey(labelValues)
+	s, ok := c.series[key]
+	if !ok {
+		s = &ringSeries{labelValues: labelValues, buffers: make(map[string]*ringBuffer)}
+		c.series[key] = s
+	}
+
+	buf, ok := s.buffers[name]
+	if !ok {
+		buf = &ringBuffer{}
+		s.buffers[name] = buf
+	}
+
+	buf.add(sample{timestamp: timestamp, value: value})
+}
+
+// allSamples flattens every label-set/metric pair's full history, for a
+// remote_write backfill to replay.
+func (c *ringBufferCollector) allSamples() []backfillSample {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var out []backfillSample
+	for _, s := range c.series {
+		for name, buf := range s.buffers {
+			for _, smp := range buf.samples {
+				out = append(out, backfillSample{
+					name:        name,
+					labelValues: append([]string(nil), s.labelValues...),
+					timestamp:   smp.timestamp,
+					value:       smp.value,
+				})
+			}
+		}
+	}
+	return out
+}
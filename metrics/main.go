@@ -96,7 +96,7 @@ func (e *Exporter) processJSONFile(filepath string) error {
 			"ef_construction": fmt.Sprintf("%d", data.EFConstruction),
 			"max_connections": fmt.Sprintf("%d", data.MaxConnections),
 			"limit":           fmt.Sprintf("%d", data.Limit),
-			"ef":             fmt.Sprintf("%d", data.EF),
+			"ef":              fmt.Sprintf("%d", data.EF),
 		}
 
 		if metric := e.metrics["latency_mean"]; metric != nil {